@@ -4,15 +4,22 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/spf13/cobra"
 
+	"github.com/HewlettPackard/terraschema/pkg/archive"
 	tsjson "github.com/HewlettPackard/terraschema/pkg/json"
 	"github.com/HewlettPackard/terraschema/pkg/jsonschema"
+	"github.com/HewlettPackard/terraschema/pkg/lint"
+	"github.com/HewlettPackard/terraschema/pkg/reader"
 )
 
 var (
@@ -22,13 +29,36 @@ var (
 	requireAll                   bool
 	outputStdOut                 bool
 	nullableAll                  bool
+	nullableNested               bool
 	inputPath                    string
 	outputPath                   string
 	debugOut                     bool
 	exportVariables              bool
+	outputs                      bool
+	lintVariables                bool
 	escapeJSON                   bool
 	ignoreVariables              []string
 	rootProperties               []string
+	childModules                 bool
+	moduleManifest               bool
+	collectErrors                bool
+	bestEffort                   bool
+	errorMessages                bool
+	sensitivePasswordFormat      bool
+	includeSensitiveDefaults     bool
+	sourceLocations              bool
+	declarationOrder             bool
+	excludeEphemeral             bool
+	terraformMetadata            bool
+	commentDescriptions          bool
+	dialectName                  string
+	dialect                      reader.Dialect
+	draftName                    string
+	openAPIComponent             bool
+	deduplicateTypes             bool
+	nullableStyleName            string
+	nullableStyle                jsonschema.NullableStyle
+	draft                        jsonschema.Draft
 )
 
 // rootCmd is the base command for terraschema
@@ -36,9 +66,9 @@ var rootCmd = &cobra.Command{
 	Use:     "terraschema",
 	Example: "terraschema -i /path/to/module -o /path/to/schema.json",
 	Short:   "Generate JSON schema from HCL Variable Blocks in a Terraform/OpenTofu module",
-	Long: "TerraSchema is a CLI tool which scans Terraform configuration ('.tf') " +
+	Long: "TerraSchema is a CLI tool which scans Terraform configuration ('.tf' and '.tf.json') " +
 		"files, parses a list of variables along with their type and validation rules, and converts " +
-		"them to a schema which complies with JSON Schema Draft-07.\nThe default behaviour is to scan " +
+		"them to a schema which complies with JSON Schema Draft-07, or 2020-12 with --draft.\nThe default behaviour is to scan " +
 		"the current directory and output a schema file called 'schema.json' in the same location. " +
 		"\nFor more information see https://github.com/HewlettPackard/terraschema.",
 	PreRunE:      preRunCommand,
@@ -51,9 +81,29 @@ var rootCmd = &cobra.Command{
 //   - overwrite: overwrite an existing file (default is false for safety reasons)
 //   - stdout: suppress errors and output schema to stdout (generally not recommended)
 //   - output: file, default is ./schema.json. Allow creation of directories.
-//   - input: folder, default is .
+//   - input: folder, default is . Archives are also accepted, e.g. module.tar.gz//modules/vpc
 //   - allow-empty: if no variables are found, print empty schema and exit with 0
 //   - require-all: require all variables to be present in the schema, even if a default value is specified
+//   - outputs: create a schema for the output of 'terraform output -json' instead of the variables
+//   - lint: report unused and undeclared variables in JSON instead of creating a schema
+//   - child-modules: add a schema for each child module with a local source to '$defs'
+//   - module-manifest: add a schema for each module installed by 'terraform init' to '$defs'
+//   - collect-errors: report all problems in the module together instead of stopping at the first one
+//   - best-effort: create a schema from the variables which could be read, and print the problems as warnings
+//   - error-messages: add validation error messages to the schema in the format used by ajv-errors
+//   - sensitive-password-format: add 'format: password' to sensitive string variables
+//   - include-sensitive-defaults: don't redact the default values of sensitive variables
+//   - source-locations: add the file and lines where each variable is declared to the output
+//   - declaration-order: keep the order variables are declared in, as 'x-order' or as a list of exported variables
+//   - exclude-ephemeral: leave ephemeral variables out of the schema
+//   - terraform-metadata: add the required Terraform and provider versions and the backend type as 'x-terraform'
+//   - comment-descriptions: use the comment above a variable or object attribute as its description, if it has none
+//   - draft: the version of JSON Schema to create, either 'draft-07' (default), '2020-12' or 'openapi-3.0'
+//   - nullable-nested: allow null for object attributes and collection elements, on by default with nullable-all
+//   - nullable-style: 'oneOf-titled' (default), 'anyOf' or 'type-array', the way nullable types are written
+//   - openapi-component: wrap the schema in 'components.schemas', under the name of the module
+//   - deduplicate-types: move object types which are repeated to shared definitions, and refer to them with '$ref'
+//   - dialect: 'terraform' (default) or 'tofu', to read .tofu files and OpenTofu only arguments
 //   - debug: output logs to track variables retrieved from each file, and get more verbose logs from custom validation rules
 func Execute() error {
 	return rootCmd.Execute()
@@ -74,7 +124,8 @@ func init() {
 	)
 
 	rootCmd.Flags().StringVarP(&inputPath, "input", "i", ".",
-		"input folder containing a Terraform module",
+		"input folder containing a Terraform module, or a .zip, .tar.gz or .tgz archive, optionally\n"+
+			"followed by '//' and a directory within the archive",
 	)
 
 	rootCmd.Flags().StringVarP(&outputPath, "output", "o", "schema.json",
@@ -99,22 +150,123 @@ func init() {
 		"make all variables nullable unless nullable set to false explicitly, to make behavior consistent with Terraform",
 	)
 
+	rootCmd.Flags().BoolVar(&nullableNested, "nullable-nested", false,
+		"allow null for object attributes, map values and the elements of lists, sets and tuples, as\n"+
+			"Terraform does. This is on by default with --nullable-all, use --nullable-nested=false to disable it",
+	)
+
 	rootCmd.Flags().BoolVar(&exportVariables, "export-variables", false,
 		"export variables to a JSON file or stdout instead of creating a schema",
 	)
 
+	rootCmd.Flags().BoolVar(&outputs, "outputs", false,
+		"create a JSON Schema for the output of 'terraform output -json' instead of the input variables",
+	)
+
+	rootCmd.Flags().BoolVar(&lintVariables, "lint", false,
+		"report the variables which are declared but never referenced, and the references to variables\n"+
+			"which are never declared, as JSON instead of creating a JSON Schema",
+	)
+
 	rootCmd.Flags().BoolVar(&escapeJSON, "escape-json", false,
 		"escape JSON special characters in the output, so that the Schema can be used in a\n"+
 			"web context",
 	)
 	rootCmd.Flags().StringSliceVar(&ignoreVariables, "ignore-variable", []string{},
 		"ignore a variable by name when generating schema or exporting variables,\n"+
-			"repeating this argument allows you to ignore multiple variables",
+			"repeating this argument allows you to ignore multiple variables. Names only apply to the\n"+
+			"root module, use the address for variables of child modules, e.g. 'module.net.cidr'",
 	)
 	rootCmd.Flags().StringSliceVar(&rootProperties, "root-property", []string{},
 		"add a property to the root of the output JSON Schema, in the format 'key=value'",
 	)
 
+	rootCmd.Flags().BoolVar(&childModules, "child-modules", false,
+		"follow module blocks with a local source path and add a schema for each child module\n"+
+			"to '$defs' in the JSON Schema, keyed by module address",
+	)
+
+	rootCmd.Flags().BoolVar(&moduleManifest, "module-manifest", false,
+		"add a schema for each module installed by 'terraform init' to '$defs' in the JSON Schema,\n"+
+			"using .terraform/modules/modules.json. This includes registry and git modules",
+	)
+
+	rootCmd.Flags().BoolVar(&collectErrors, "collect-errors", false,
+		"read every file in the module and report all problems together, instead of stopping\n"+
+			"at the first one",
+	)
+
+	rootCmd.Flags().BoolVar(&bestEffort, "best-effort", false,
+		"create a schema from the variables which could be read, and print the problems found\n"+
+			"as warnings. With --export-variables, the variables which could be read are exported.\n"+
+			"Implies --collect-errors",
+	)
+
+	rootCmd.Flags().BoolVar(&errorMessages, "error-messages", false,
+		"add the error_message of each validation block to an 'errorMessage' keyword, in the\n"+
+			"format used by ajv-errors",
+	)
+
+	rootCmd.Flags().BoolVar(&sensitivePasswordFormat, "sensitive-password-format", false,
+		"add 'format: password' to sensitive string variables, so that form generators render\n"+
+			"a masked input",
+	)
+
+	rootCmd.Flags().BoolVar(&includeSensitiveDefaults, "include-sensitive-defaults", false,
+		"include the default values of sensitive variables in the output, instead of redacting them",
+	)
+
+	rootCmd.Flags().BoolVar(&sourceLocations, "source-locations", false,
+		"add the file and lines where each variable is declared to the output, as\n"+
+			"'x-terraform-source' in the JSON Schema or 'source' when exporting variables",
+	)
+
+	rootCmd.Flags().BoolVar(&declarationOrder, "declaration-order", false,
+		"keep the order the variables are declared in the module, as 'x-order' in the JSON Schema,\n"+
+			"or by exporting the variables as a list instead of an object",
+	)
+
+	rootCmd.Flags().BoolVar(&excludeEphemeral, "exclude-ephemeral", false,
+		"leave variables with 'ephemeral = true' out of the JSON Schema",
+	)
+
+	rootCmd.Flags().BoolVar(&terraformMetadata, "terraform-metadata", false,
+		"add the versions of Terraform and the providers required by the module, and its backend\n"+
+			"type, to the root of the JSON Schema as 'x-terraform'",
+	)
+
+	rootCmd.Flags().BoolVar(&commentDescriptions, "comment-descriptions", false,
+		"use the comment above a variable block as its description if it doesn't have one. Comments\n"+
+			"above the attributes of object types become descriptions of the nested properties",
+	)
+
+	rootCmd.Flags().StringVar(&draftName, "draft", string(jsonschema.Draft07),
+		"the version of JSON Schema to create, either 'draft-07', '2020-12' or 'openapi-3.0'. With\n"+
+			"'2020-12', tuples use 'prefixItems' and the '$schema' URI of 2020-12. With 'openapi-3.0',\n"+
+			"an OpenAPI 3.0 schema object is created, using 'nullable' instead of the 'null' type",
+	)
+
+	rootCmd.Flags().StringVar(&nullableStyleName, "nullable-style", string(jsonschema.NullableOneOfTitled),
+		"the way nullable types are written, either 'oneOf-titled', 'anyOf' or 'type-array'. 'oneOf-titled'\n"+
+			"adds titles for form generators such as react-jsonschema-form, 'anyOf' has no titles, and\n"+
+			"'type-array' adds 'null' to the list of types, e.g. \"type\": [\"string\", \"null\"]",
+	)
+
+	rootCmd.Flags().BoolVar(&openAPIComponent, "openapi-component", false,
+		"wrap the schema as an entry in 'components.schemas', named after the module directory, so that\n"+
+			"it can be merged into an OpenAPI specification. Requires '--draft openapi-3.0'",
+	)
+
+	rootCmd.Flags().BoolVar(&deduplicateTypes, "deduplicate-types", false,
+		"move object types which appear more than once to shared definitions, named after the first\n"+
+			"variable or attribute which uses them, and replace each copy with a '$ref'",
+	)
+
+	rootCmd.Flags().StringVar(&dialectName, "dialect", string(reader.DialectTerraform),
+		"the tool the module is written for, either 'terraform' or 'tofu'. With 'tofu', .tofu and\n"+
+			".tofu.json files are read and replace .tf and .tf.json files with the same name",
+	)
+
 	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
 		_ = rootCmd.Usage()
 
@@ -123,7 +275,36 @@ func init() {
 }
 
 func preRunCommand(cmd *cobra.Command, args []string) error {
-	err := inputFileChecks()
+	var err error
+	dialect, err = reader.ParseDialect(dialectName)
+	if err != nil {
+		return err
+	}
+	draft, err = jsonschema.ParseDraft(draftName)
+	if err != nil {
+		return err
+	}
+	nullableStyle, err = jsonschema.ParseNullableStyle(nullableStyleName)
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("nullable-nested") {
+		nullableNested = nullableAll
+	}
+	if openAPIComponent && draft != jsonschema.OpenAPI30 {
+		return errors.New("--openapi-component can only be used with --draft openapi-3.0")
+	}
+	if moduleManifest && childModules {
+		return errors.New("--module-manifest can't be used with --child-modules, since it already includes local modules")
+	}
+	if outputs && exportVariables {
+		return errors.New("--outputs can't be used with --export-variables")
+	}
+	if lintVariables && (outputs || exportVariables) {
+		return errors.New("--lint can't be used with --outputs or --export-variables")
+	}
+
+	err = inputFileChecks()
 	if err != nil {
 		return err
 	}
@@ -140,6 +321,18 @@ func inputFileChecks() error {
 		return fmt.Errorf("could not get absolute path for %q: %w", inputPath, err)
 	}
 
+	if archivePath, _, ok := archive.SplitInput(inputPath); ok {
+		file, err := os.Stat(archivePath)
+		if err != nil {
+			return fmt.Errorf("could not access archive %q: %w", archivePath, err)
+		}
+		if file.IsDir() {
+			return fmt.Errorf("input path %q is a directory, not an archive", archivePath)
+		}
+
+		return nil
+	}
+
 	folder, err := os.Stat(inputPath)
 	if err != nil {
 		return fmt.Errorf("could not access directory %q: %w", inputPath, err)
@@ -185,34 +378,36 @@ func runCommand(cmd *cobra.Command, args []string) error {
 
 	jsonIndent := "\t"
 
-	if exportVariables {
-		if len(rootProperties) != 0 {
-			fmt.Println("Warning: setting root properties is not supported for exporting variables, they will be ignored")
+	fsys, dir, err := openInput()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case exportVariables:
+		outputMap, err = runExportVariables(fsys, dir, jsonIndent)
+		err = checkPartialResult(err, tsjson.ErrPartialExport, "exporting variables")
+		if err != nil {
+			return err
+		}
+	case outputs:
+		outputMap, err = runCreateOutputSchema(fsys, dir)
+		if err != nil {
+			return fmt.Errorf("error creating output schema: %w", printDiagnostics(err))
 		}
-		outputMap, err = tsjson.ExportVariables(inputPath, tsjson.ExportVariablesOptions{
-			AllowEmpty:      allowEmpty,
-			SuppressLogging: outputStdOut,
-			DebugOut:        debugOut && !outputStdOut,
-			EscapeJSON:      escapeJSON,
-			Indent:          jsonIndent,
+	case lintVariables:
+		outputMap, err = lint.AnalyseVariablesFS(fsys, dir, lint.AnalyseVariablesOptions{
 			IgnoreVariables: ignoreVariables,
+			Dialect:         dialect,
 		})
 		if err != nil {
-			return fmt.Errorf("error exporting variables: %w", err)
+			return fmt.Errorf("error analysing variables: %w", printDiagnostics(err))
 		}
-	} else {
-		outputMap, err = jsonschema.CreateSchema(inputPath, jsonschema.CreateSchemaOptions{
-			RequireAll:                requireAll,
-			AllowAdditionalProperties: !disallowAdditionalProperties,
-			AllowEmpty:                allowEmpty,
-			DebugOut:                  debugOut && !outputStdOut,
-			SuppressLogging:           outputStdOut,
-			NullableAll:               nullableAll,
-			IgnoreVariables:           ignoreVariables,
-			RootProperties:            parseProperties(),
-		})
+	default:
+		outputMap, err = runCreateSchema(fsys, dir)
+		err = checkPartialResult(err, jsonschema.ErrPartialSchema, "creating schema")
 		if err != nil {
-			return fmt.Errorf("error creating schema: %w", err)
+			return err
 		}
 	}
 
@@ -251,6 +446,170 @@ func runCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// checkPartialResult returns nil if err wraps partialErr, which means that the output only contains the variables
+// which could be read, after printing the problems found as warnings. Other errors are returned with their
+// diagnostics printed.
+func checkPartialResult(err error, partialErr error, action string) error {
+	if errors.Is(err, partialErr) {
+		_ = printDiagnostics(err)
+		if !outputStdOut {
+			fmt.Printf("Warning: %v\n", partialErr)
+		}
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error %s: %w", action, printDiagnostics(err))
+	}
+
+	return nil
+}
+
+// openInput returns the file system and directory containing the input module. Archives are read into memory, and
+// anything else is read from disk.
+func openInput() (fs.FS, string, error) {
+	archivePath, dir, ok := archive.SplitInput(inputPath)
+	if !ok {
+		return reader.LocalFS{}, filepath.ToSlash(inputPath), nil
+	}
+
+	fsys, err := archive.Open(archivePath, dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return fsys, dir, nil
+}
+
+// printWarning prints a warning, unless the output is written to stdout, where it would be mixed in with the JSON.
+func printWarning(message string) {
+	if !outputStdOut {
+		fmt.Printf("Warning: %s\n", message)
+	}
+}
+
+// runExportVariables exports the variables of the input module, as an object keyed by name or as a list in
+// declaration order.
+func runExportVariables(fsys fs.FS, dir string, jsonIndent string) (any, error) {
+	if len(rootProperties) != 0 {
+		fmt.Println("Warning: setting root properties is not supported for exporting variables, they will be ignored")
+	}
+	if childModules || moduleManifest {
+		printWarning("child modules are not supported for exporting variables, they will be ignored")
+	}
+	if excludeEphemeral {
+		printWarning("excluding ephemeral variables is not supported for exporting variables, it will be ignored")
+	}
+	exportOptions := tsjson.ExportVariablesOptions{
+		AllowEmpty:               allowEmpty,
+		SuppressLogging:          outputStdOut,
+		DebugOut:                 debugOut && !outputStdOut,
+		EscapeJSON:               escapeJSON,
+		Indent:                   jsonIndent,
+		IgnoreVariables:          ignoreVariables,
+		CollectErrors:            collectErrors,
+		BestEffort:               bestEffort,
+		IncludeSensitiveDefaults: includeSensitiveDefaults,
+		SourceLocations:          sourceLocations,
+		Dialect:                  dialect,
+		CommentDescriptions:      commentDescriptions,
+	}
+	if declarationOrder {
+		return tsjson.ExportVariablesOrderedFS(fsys, dir, exportOptions)
+	}
+
+	return tsjson.ExportVariablesFS(fsys, dir, exportOptions)
+}
+
+func runCreateSchema(fsys fs.FS, dir string) (map[string]any, error) {
+	return jsonschema.CreateSchemaFS(fsys, dir, createSchemaOptions())
+}
+
+// runCreateOutputSchema creates a schema for the outputs of the input module. Only the options which affect the
+// types of values are used.
+func runCreateOutputSchema(fsys fs.FS, dir string) (map[string]any, error) {
+	if childModules || moduleManifest {
+		printWarning("child modules are not supported for output schemas, they will be ignored")
+	}
+
+	return jsonschema.CreateOutputSchemaFS(fsys, dir, createSchemaOptions())
+}
+
+func createSchemaOptions() jsonschema.CreateSchemaOptions {
+	return jsonschema.CreateSchemaOptions{
+		RequireAll:                requireAll,
+		AllowAdditionalProperties: !disallowAdditionalProperties,
+		AllowEmpty:                allowEmpty,
+		DebugOut:                  debugOut && !outputStdOut,
+		SuppressLogging:           outputStdOut,
+		NullableAll:               nullableAll,
+		IgnoreVariables:           ignoreVariables,
+		RootProperties:            parseProperties(),
+		ChildModules:              childModules,
+		ModuleManifest:            moduleManifest,
+		CollectErrors:             collectErrors,
+		BestEffort:                bestEffort,
+		ErrorMessages:             errorMessages,
+		SensitivePasswordFormat:   sensitivePasswordFormat,
+		IncludeSensitiveDefaults:  includeSensitiveDefaults,
+		SourceLocations:           sourceLocations,
+		DeclarationOrder:          declarationOrder,
+		ExcludeEphemeral:          excludeEphemeral,
+		TerraformMetadata:         terraformMetadata,
+		Dialect:                   dialect,
+		CommentDescriptions:       commentDescriptions,
+		Draft:                     draft,
+		OpenAPIComponent:          componentName(),
+		DeduplicateTypes:          deduplicateTypes,
+		NullableStyle:             nullableStyle,
+		NullableNested:            nullableNested,
+	}
+}
+
+// componentName returns the name of the OpenAPI component to wrap the schema in, which is the name of the module
+// directory, or of the archive if the module is in its root. It returns an empty string if --openapi-component isn't
+// set.
+func componentName() string {
+	if !openAPIComponent {
+		return ""
+	}
+
+	archivePath, dir, ok := archive.SplitInput(inputPath)
+	if !ok {
+		abs, err := filepath.Abs(inputPath)
+		if err != nil {
+			return filepath.Base(inputPath)
+		}
+
+		return filepath.Base(abs)
+	}
+	if dir != "." {
+		return path.Base(dir)
+	}
+	name := filepath.Base(archivePath)
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	return name
+}
+
+// printDiagnostics prints any HCL diagnostics contained in err to stderr, with the file, line and a snippet of the
+// source code for each of them. If there are diagnostics, a shorter error is returned, since the details have
+// already been printed.
+func printDiagnostics(err error) error {
+	var diagErr *reader.DiagnosticsError
+	if !errors.As(err, &diagErr) {
+		return err
+	}
+
+	writer := hcl.NewDiagnosticTextWriter(os.Stderr, diagErr.Files, 0, false)
+	if writeErr := writer.WriteDiagnostics(diagErr.Diagnostics); writeErr != nil {
+		return err
+	}
+
+	return fmt.Errorf("found %d problem(s) in the Terraform configuration", len(diagErr.Diagnostics))
+}
+
 func parseProperties() map[string]string {
 	properties := make(map[string]string)
 	for _, prop := range rootProperties {
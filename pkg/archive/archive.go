@@ -0,0 +1,138 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// extensions are the archive formats which can be read, in the order they are checked.
+var extensions = []string{".tar.gz", ".tgz", ".zip"}
+
+// maxFileSize is the largest file which is read from a tarball. Files in a tarball are read into memory, so this
+// stops a corrupt or malicious archive from using all of it. Terraform files are much smaller than this.
+const maxFileSize = 64 << 20
+
+var (
+	ErrNotDirectory = errors.New("not a directory in the archive")
+	ErrFileTooLarge = errors.New("file in the archive is too large")
+)
+
+// SplitInput splits an input path into the path of an archive and a directory within it, using the same syntax as
+// Terraform module sources, e.g. "module.tar.gz//modules/vpc". The directory is "." if none is given. It returns
+// false if the input doesn't refer to a .zip, .tar.gz or .tgz file, including if it is a directory with one of these
+// extensions.
+func SplitInput(input string) (string, string, bool) {
+	for _, ext := range extensions {
+		if i := strings.Index(input, ext+"//"); i != -1 {
+			archivePath := input[:i+len(ext)]
+			if isDir(archivePath) {
+				return "", "", false
+			}
+			dir := strings.Trim(filepath.ToSlash(input[i+len(ext)+2:]), "/")
+			if dir == "" {
+				dir = "."
+			}
+
+			return archivePath, path.Clean(dir), true
+		}
+		if strings.HasSuffix(input, ext) && !isDir(input) {
+			return input, ".", true
+		}
+	}
+
+	return "", "", false
+}
+
+// isDir returns true if p is a directory. If it doesn't exist, it is treated as an archive, so that the error
+// reported when it is opened refers to the archive.
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+
+	return err == nil && info.IsDir()
+}
+
+// Open reads an archive into memory and returns its contents as an fs.FS, without extracting it to disk. The format
+// of the archive is decided by its extension. If dir isn't "." it must be a directory in the archive.
+func Open(archivePath string, dir string) (fs.FS, error) {
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read archive %q: %w", archivePath, err)
+	}
+
+	var fsys fs.FS
+	if strings.HasSuffix(archivePath, ".zip") {
+		fsys, err = zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	} else {
+		fsys, err = readTarGz(bytes.NewReader(data), maxFileSize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open archive %q: %w", archivePath, err)
+	}
+
+	info, err := fs.Stat(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not access %q in archive %q: %w", dir, archivePath, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%q in archive %q: %w", dir, archivePath, ErrNotDirectory)
+	}
+
+	return fsys, nil
+}
+
+// readTarGz reads a gzipped tarball into memory. The standard library doesn't provide an fs.FS for tar files, so
+// the regular files in the tarball are copied to an uncompressed zip archive instead, since zip.Reader does.
+// Symbolic links and other special files are skipped. Files larger than maxSize are an error.
+func readTarGz(r io.Reader, maxSize int64) (fs.FS, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	buffer := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(buffer)
+	tarReader := tar.NewReader(gz)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "/"))
+		if header.Typeflag != tar.TypeReg || !fs.ValidPath(name) {
+			continue
+		}
+		w, err := zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: header.ModTime})
+		if err != nil {
+			return nil, err
+		}
+		// the size in the header can't be trusted, so the file is read up to one byte past the limit instead.
+		n, err := io.Copy(w, io.LimitReader(tarReader, maxSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if n > maxSize {
+			return nil, fmt.Errorf("%q is larger than %d bytes: %w", name, maxSize, ErrFileTooLarge)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	return zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+}
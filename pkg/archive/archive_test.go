@@ -0,0 +1,148 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/HewlettPackard/terraschema/pkg/jsonschema"
+)
+
+func TestSplitInput(t *testing.T) {
+	t.Parallel()
+	// a directory with the extension of an archive is read as a module directory.
+	dir := filepath.Join(t.TempDir(), "module.zip")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+
+	testCases := []struct {
+		input       string
+		archivePath string
+		dir         string
+		ok          bool
+	}{
+		{"module.tar.gz", "module.tar.gz", ".", true},
+		{"/tmp/module.tgz//modules/vpc", "/tmp/module.tgz", "modules/vpc", true},
+		{"module.zip//modules/vpc/", "module.zip", "modules/vpc", true},
+		{"module.zip//", "module.zip", ".", true},
+		{"./modules/vpc", "", "", false},
+		{"module.tar", "", "", false},
+		{dir, "", "", false},
+		{dir + "//modules/vpc", "", "", false},
+	}
+	for _, tc := range testCases {
+		archivePath, dir, ok := SplitInput(tc.input)
+		require.Equal(t, tc.ok, ok, tc.input)
+		require.Equal(t, tc.archivePath, archivePath, tc.input)
+		require.Equal(t, tc.dir, dir, tc.input)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+	modulePath := "../../test/modules/simple"
+	expected, err := jsonschema.CreateSchema(modulePath, jsonschema.CreateSchemaOptions{})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	archives := map[string]func(t *testing.T, archivePath string, files map[string][]byte){
+		"module.zip":    writeZip,
+		"module.tar.gz": writeTarGz,
+	}
+	files := readModule(t, modulePath, "modules/simple")
+	for name, write := range archives {
+		archivePath := filepath.Join(dir, name)
+		write(t, archivePath, files)
+
+		fsys, err := Open(archivePath, "modules/simple")
+		require.NoError(t, err)
+
+		result, err := jsonschema.CreateSchemaFS(fsys, "modules/simple", jsonschema.CreateSchemaOptions{})
+		require.NoError(t, err)
+		require.Equal(t, expected, result, name)
+
+		_, err = Open(archivePath, "modules/missing")
+		require.ErrorIs(t, err, fs.ErrNotExist, name)
+
+		_, err = Open(archivePath, "modules/simple/variables.tf")
+		require.ErrorIs(t, err, ErrNotDirectory, name)
+	}
+}
+
+func TestOpen_FileTooLarge(t *testing.T) {
+	t.Parallel()
+	archivePath := filepath.Join(t.TempDir(), "module.tar.gz")
+	writeTarGz(t, archivePath, map[string][]byte{"variables.tf": []byte("variable \"a\" {}\n")})
+	data, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+
+	_, err = readTarGz(bytes.NewReader(data), 8)
+	require.ErrorIs(t, err, ErrFileTooLarge)
+
+	fsys, err := readTarGz(bytes.NewReader(data), 1024)
+	require.NoError(t, err)
+	_, err = fs.Stat(fsys, "variables.tf")
+	require.NoError(t, err)
+}
+
+// readModule reads the files in the root of a module on disk, and names them as if they were in dir.
+func readModule(t *testing.T, modulePath string, dir string) map[string][]byte {
+	t.Helper()
+	entries, err := os.ReadDir(modulePath)
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(modulePath, entry.Name()))
+		require.NoError(t, err)
+		files[dir+"/"+entry.Name()] = data
+	}
+
+	return files
+}
+
+func writeZip(t *testing.T, archivePath string, files map[string][]byte) {
+	t.Helper()
+	out, err := os.Create(archivePath)
+	require.NoError(t, err)
+	defer out.Close()
+
+	w := zip.NewWriter(out)
+	for name, data := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+}
+
+func writeTarGz(t *testing.T, archivePath string, files map[string][]byte) {
+	t.Helper()
+	out, err := os.Create(archivePath)
+	require.NoError(t, err)
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	w := tar.NewWriter(gz)
+	// tarballs often contain entries for directories and names starting with "./", which should be handled.
+	require.NoError(t, w.WriteHeader(&tar.Header{Name: "./modules/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for name, data := range files {
+		err := w.WriteHeader(&tar.Header{Name: "./" + name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data))})
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, gz.Close())
+}
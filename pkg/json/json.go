@@ -2,9 +2,12 @@ package json
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
+	"path/filepath"
 	"slices"
 
 	"github.com/HewlettPackard/terraschema/pkg/model"
@@ -20,72 +23,181 @@ type ExportVariablesOptions struct {
 	EscapeJSON      bool
 	Indent          string
 	IgnoreVariables []string
+	// CollectErrors reads every file in the module and reports all the problems found together, instead of
+	// stopping at the first one.
+	CollectErrors bool
+	// BestEffort exports the variables which could be read, even if there are problems with the module. It implies
+	// CollectErrors. The variables are returned along with an error wrapping ErrPartialExport.
+	BestEffort bool
+	// IncludeSensitiveDefaults keeps the default value of sensitive variables in the output. By default, they are
+	// redacted so that secrets in the module aren't published with the variables.
+	IncludeSensitiveDefaults bool
+	// SourceLocations adds the file and lines where each variable is declared to the output.
+	SourceLocations bool
+	// Dialect decides which files are read, and which arguments are allowed in variable blocks. The default is
+	// reader.DialectTerraform.
+	Dialect reader.Dialect
+	// CommentDescriptions uses the comment above a variable block as its description, if it doesn't have a
+	// description argument.
+	CommentDescriptions bool
 }
 
 type MarshallableVariableBlock struct {
 	model.TranslatedVariable
+	// Name is added to the output if it is set. It is used when the variables are exported as an ordered list,
+	// since they can't be identified by their key.
+	Name       string
 	EscapeHTML bool
 	Indent     string
+	// RedactDefault omits the default value from the output.
+	RedactDefault bool
+	// IncludeSource adds the location of the variable block to the output.
+	IncludeSource bool
 }
 
 var _ json.Marshaler = MarshallableVariableBlock{}
 
 type JSONVariableBlock struct {
+	Name        string                `json:"name,omitempty"`
 	Default     *any                  `json:"default,omitempty"`
 	Description *string               `json:"description,omitempty"`
 	Nullable    *bool                 `json:"nullable,omitempty"`
 	Sensitive   *bool                 `json:"sensitive,omitempty"`
+	Ephemeral   *bool                 `json:"ephemeral,omitempty"`
 	Validations []JSONValidationBlock `json:"validation,omitempty"`
 	Type        *any                  `json:"type,omitempty"`
+	Deprecated  *string               `json:"deprecated,omitempty"`
+	// TypeDefaults contains the default values of optional object attributes declared in the type.
+	TypeDefaults *reader.TypeDefaults  `json:"type_defaults,omitempty"`
+	Source       *model.SourceLocation `json:"source,omitempty"`
 }
 
 type JSONValidationBlock struct {
-	Condition string `json:"condition"`
+	Condition    string `json:"condition"`
+	ErrorMessage string `json:"error_message,omitempty"`
 }
 
+// ErrPartialExport is returned alongside the variables when BestEffort is set and some variables couldn't be read.
+var ErrPartialExport = errors.New("only the variables which could be read were exported")
+
 func ExportVariables(path string, options ExportVariablesOptions) (map[string]MarshallableVariableBlock, error) {
+	return ExportVariablesFS(reader.LocalFS{}, filepath.ToSlash(path), options)
+}
+
+// ExportVariablesFS is the same as ExportVariables, but reads the module in the directory dir of fsys.
+func ExportVariablesFS(fsys fs.FS, dir string, options ExportVariablesOptions) (map[string]MarshallableVariableBlock, error) {
 	jsonMap := make(map[string]MarshallableVariableBlock)
-	varMap, err := reader.GetVarMap(path, options.DebugOut)
-	if err != nil {
-		if options.AllowEmpty && (errors.Is(err, reader.ErrFilesNotFound) || errors.Is(err, reader.ErrNoVariablesFound)) {
-			if !options.SuppressLogging {
-				fmt.Printf("Warning: directory %q: %v, creating empty variables file\n", path, err)
-			}
+	varMap, readErr := getVarMap(fsys, dir, options)
+	if readErr != nil && !errors.Is(readErr, ErrPartialExport) {
+		return jsonMap, readErr
+	}
 
-			return jsonMap, nil
-		} else {
-			return jsonMap, fmt.Errorf("error reading tf files at %q: %w", path, err)
+	for k, v := range varMap {
+		if slices.Contains(options.IgnoreVariables, k) {
+			continue
 		}
+		jsonMap[k] = newMarshallableVariableBlock(v, options)
 	}
 
+	return jsonMap, readErr
+}
+
+// ExportVariablesOrdered is the same as ExportVariables, but returns the variables as a list in the order they are
+// declared in the module. Since they aren't keyed by name, each variable has a "name" field in the output.
+func ExportVariablesOrdered(path string, options ExportVariablesOptions) ([]MarshallableVariableBlock, error) {
+	return ExportVariablesOrderedFS(reader.LocalFS{}, filepath.ToSlash(path), options)
+}
+
+// ExportVariablesOrderedFS is the same as ExportVariablesOrdered, but reads the module in the directory dir of fsys.
+func ExportVariablesOrderedFS(fsys fs.FS, dir string, options ExportVariablesOptions) ([]MarshallableVariableBlock, error) {
+	varMap, readErr := getVarMap(fsys, dir, options)
+	if readErr != nil && !errors.Is(readErr, ErrPartialExport) {
+		return []MarshallableVariableBlock{}, readErr
+	}
+
+	jsonList := make([]MarshallableVariableBlock, 0, len(varMap))
+
 	for k, v := range varMap {
 		if slices.Contains(options.IgnoreVariables, k) {
 			continue
 		}
-		jsonMap[k] = MarshallableVariableBlock{v, options.EscapeJSON, options.Indent}
+		block := newMarshallableVariableBlock(v, options)
+		block.Name = k
+		jsonList = append(jsonList, block)
 	}
+	slices.SortFunc(jsonList, func(a, b MarshallableVariableBlock) int {
+		return cmp.Compare(a.Order, b.Order)
+	})
 
-	return jsonMap, nil
+	return jsonList, readErr
+}
+
+// getVarMap reads the variables in a module. If AllowEmpty is set and the module has no variables, an empty map is
+// returned instead of an error. If BestEffort is set, the variables which could be read are returned along with an
+// error wrapping ErrPartialExport.
+func getVarMap(fsys fs.FS, dir string, options ExportVariablesOptions) (map[string]model.TranslatedVariable, error) {
+	varMap, err := reader.GetVarMapFS(fsys, dir, reader.GetVarMapOptions{
+		DebugOut:            options.DebugOut,
+		ContinueOnError:     options.CollectErrors || options.BestEffort,
+		Dialect:             options.Dialect,
+		CommentDescriptions: options.CommentDescriptions,
+	})
+	if err != nil && options.BestEffort && varMap != nil {
+		return varMap, fmt.Errorf("%w: %w", ErrPartialExport, err)
+	}
+	if err != nil {
+		if options.AllowEmpty && (errors.Is(err, reader.ErrFilesNotFound) || errors.Is(err, reader.ErrNoVariablesFound)) {
+			if !options.SuppressLogging {
+				fmt.Printf("Warning: directory %q: %v, creating empty variables file\n", dir, err)
+			}
+
+			return map[string]model.TranslatedVariable{}, nil
+		} else {
+			return nil, fmt.Errorf("error reading tf files at %q: %w", dir, err)
+		}
+	}
+
+	return varMap, nil
+}
+
+func newMarshallableVariableBlock(v model.TranslatedVariable, options ExportVariablesOptions) MarshallableVariableBlock {
+	return MarshallableVariableBlock{
+		TranslatedVariable: v,
+		EscapeHTML:         options.EscapeJSON,
+		Indent:             options.Indent,
+		RedactDefault:      v.Variable.IsSensitive() && !options.IncludeSensitiveDefaults,
+		IncludeSource:      options.SourceLocations,
+	}
 }
 
 func (j MarshallableVariableBlock) MarshalJSON() ([]byte, error) {
 	translatedBlock := JSONVariableBlock{
+		Name:        j.Name,
 		Description: j.Variable.Description,
 		Nullable:    j.Variable.Nullable,
 		Sensitive:   j.Variable.Sensitive,
+		Ephemeral:   j.Variable.Ephemeral,
+		Deprecated:  j.Variable.Deprecated,
+	}
+	if j.IncludeSource {
+		translatedBlock.Source = &j.Location
 	}
 
-	translatedType, err := reader.GetTypeConstraint(j.Variable.Type)
+	translatedType, typeDefaults, err := reader.GetTypeConstraintWithDefaults(j.Variable.Type)
 	if err != nil {
 		return nil, fmt.Errorf("error marshalling type constraint: %w", err)
 	}
 	translatedBlock.Type = &translatedType
 
-	translatedDefault, err := reader.ExpressionToJSONObject(j.Variable.Default)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling default expression: %w", err)
+	// the defaults of optional attributes are redacted along with the default of the variable.
+	if !j.RedactDefault {
+		translatedBlock.TypeDefaults = typeDefaults
+		translatedDefault, err := reader.ExpressionToJSONObject(j.Variable.Default)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling default expression: %w", err)
+		}
+		translatedBlock.Default = &translatedDefault
 	}
-	translatedBlock.Default = &translatedDefault
 
 	if len(j.Variable.Validations) != 0 {
 		if len(j.ConditionsAsString) != len(j.Variable.Validations) {
@@ -96,6 +208,9 @@ func (j MarshallableVariableBlock) MarshalJSON() ([]byte, error) {
 			translatedBlock.Validations[i] = JSONValidationBlock{
 				Condition: j.ConditionsAsString[i],
 			}
+			if i < len(j.ErrorMessagesAsString) {
+				translatedBlock.Validations[i].ErrorMessage = j.ErrorMessagesAsString[i]
+			}
 		}
 	}
 
@@ -5,9 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+
+	"github.com/HewlettPackard/terraschema/pkg/reader"
 )
 
 func TestCreateSchema(t *testing.T) {
@@ -21,6 +24,19 @@ func TestCreateSchema(t *testing.T) {
 		"complex-types",
 		"custom-validation",
 		"ignore-variables",
+		"child-modules",
+		"json-syntax",
+		"override-files",
+		"sensitive",
+		"optional-defaults",
+		"module-manifest",
+		"tofu",
+		"ephemeral",
+		"outputs",
+		"terraform-metadata",
+		"annotations",
+		"unused-variables",
+		"repeated-types",
 	}
 	for i := range testCases {
 		name := testCases[i]
@@ -57,3 +73,108 @@ func TestCreateSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestExportVariablesIncludeSensitiveDefaults(t *testing.T) {
+	t.Parallel()
+	tfPath := "../../test/modules/sensitive"
+
+	for _, include := range []bool{false, true} {
+		result, err := ExportVariables(tfPath, ExportVariablesOptions{IncludeSensitiveDefaults: include})
+		require.NoError(t, err)
+
+		buf, err := json.Marshal(result)
+		require.NoError(t, err)
+
+		var gotMap map[string]map[string]any
+		err = json.Unmarshal(buf, &gotMap)
+		require.NoError(t, err)
+
+		require.Equal(t, "visible", gotMap["not_sensitive"]["default"])
+		if include {
+			require.Equal(t, "hunter2", gotMap["password"]["default"])
+			require.Equal(t, map[string]any{"default_values": map[string]any{"password": "hunter2"}},
+				gotMap["database"]["type_defaults"])
+		} else {
+			require.NotContains(t, gotMap["password"], "default")
+			require.NotContains(t, gotMap["credentials"], "default")
+			require.NotContains(t, gotMap["database"], "type_defaults")
+		}
+	}
+}
+
+func TestExportVariablesOrdered(t *testing.T) {
+	t.Parallel()
+	result, err := ExportVariablesOrdered("../../test/modules/override-files", ExportVariablesOptions{
+		IgnoreVariables: []string{"age"},
+	})
+	require.NoError(t, err)
+
+	buf, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var gotList []map[string]any
+	err = json.Unmarshal(buf, &gotList)
+	require.NoError(t, err)
+
+	require.Len(t, gotList, 2)
+	require.Equal(t, "name", gotList[0]["name"])
+	require.Equal(t, "region", gotList[1]["name"])
+	require.Equal(t, "eu-west-1", gotList[1]["default"])
+}
+
+func TestExportVariablesFS(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"module/variables.tf": {Data: []byte("variable \"b\" {\n  default = 1\n}\n\nvariable \"a\" {}\n")},
+	}
+
+	result, err := ExportVariablesFS(fsys, "module", ExportVariablesOptions{})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	require.Equal(t, "1", *result["b"].DefaultAsString)
+
+	ordered, err := ExportVariablesOrderedFS(fsys, "module", ExportVariablesOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "b", ordered[0].Name)
+	require.Equal(t, "a", ordered[1].Name)
+}
+
+func TestExportVariablesBestEffort(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"variables.tf": {Data: []byte("variable \"cidr\" {\n  type = string\n}\n")},
+		"broken.tf":    {Data: []byte("variable \"broken\" {\n")},
+	}
+
+	_, err := ExportVariablesFS(fsys, ".", ExportVariablesOptions{})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrPartialExport)
+
+	result, err := ExportVariablesFS(fsys, ".", ExportVariablesOptions{BestEffort: true})
+	require.ErrorIs(t, err, ErrPartialExport)
+	var diagErr *reader.DiagnosticsError
+	require.ErrorAs(t, err, &diagErr)
+	require.Len(t, result, 1)
+	require.Contains(t, result, "cidr")
+
+	ordered, err := ExportVariablesOrderedFS(fsys, ".", ExportVariablesOptions{BestEffort: true})
+	require.ErrorIs(t, err, ErrPartialExport)
+	require.Len(t, ordered, 1)
+	require.Equal(t, "cidr", ordered[0].Name)
+}
+
+func TestExportVariablesWithDialect(t *testing.T) {
+	t.Parallel()
+	result, err := ExportVariables("../../test/modules/tofu", ExportVariablesOptions{Dialect: reader.DialectOpenTofu})
+	require.NoError(t, err)
+
+	buf, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var gotMap map[string]map[string]any
+	err = json.Unmarshal(buf, &gotMap)
+	require.NoError(t, err)
+
+	require.Equal(t, "Use name instead.", gotMap["old_name"]["deprecated"])
+	require.Equal(t, "tofu", gotMap["name"]["default"])
+}
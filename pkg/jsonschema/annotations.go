@@ -0,0 +1,218 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+	"github.com/HewlettPackard/terraschema/pkg/reader"
+)
+
+var ErrInvalidAnnotation = errors.New("invalid @schema annotation")
+
+// typeKeywords are the keywords which only apply to values of a particular type. For nullable nodes, they are added
+// to the branch for the type, rather than next to "oneOf" or "anyOf", see typeBranchOf. Other keywords, such as
+// "title" and "readOnly", describe the whole node.
+var typeKeywords = []string{
+	"format", "pattern", "minLength", "maxLength", "contentEncoding", "contentMediaType",
+	"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+	"minItems", "maxItems", "uniqueItems", "minProperties", "maxProperties",
+	"enum", "const",
+}
+
+// applyCommentAnnotations merges the keywords set by @schema comments above a variable block, and above the
+// attributes in its type, into the schema for the variable. Annotations which can't be parsed are skipped with a
+// warning, so that a mistake in a comment doesn't stop the schema from being created. If the comments above the
+// attributes have been read as descriptions, they are added to the nested schemas as well.
+func applyCommentAnnotations(name string, node map[string]any, v model.TranslatedVariable, options CreateSchemaOptions) {
+	apply := func(path string, n map[string]any, comments []string) {
+		keywords, err := parseAnnotations(comments)
+		if err != nil && !options.SuppressLogging {
+			fmt.Printf("Warning: couldn't apply annotation for %q: %v\n", path, err)
+		}
+		branch := typeBranchOf(n)
+		for keyword, value := range keywords {
+			if slices.Contains(typeKeywords, keyword) {
+				branch[keyword] = value
+			} else {
+				n[keyword] = value
+			}
+		}
+	}
+
+	apply(name, node, v.Comments)
+	walkTypeComments(name, node, v.TypeComments, func(path string, n map[string]any, comments *model.TypeComments) {
+		if _, ok := n["description"]; !ok && comments.Description != nil {
+			n["description"] = *comments.Description
+		}
+		apply(path, n, comments.Comments)
+	})
+}
+
+// walkTypeComments calls f for each nested node in the schema which has comments. The path passed to f is the name of
+// the variable followed by the keys of the nested types, e.g. "users..name" for the attribute "name" of the
+// elements of a list variable "users".
+func walkTypeComments(
+	path string,
+	node map[string]any,
+	comments *model.TypeComments,
+	f func(path string, node map[string]any, comments *model.TypeComments),
+) {
+	if comments == nil {
+		return
+	}
+	for _, key := range slices.Sorted(maps.Keys(comments.Children)) {
+		child := comments.Children[key]
+		childPath := path + "." + key
+		for _, childNode := range nestedNodes(node, key) {
+			if len(child.Comments) != 0 {
+				f(childPath, childNode, child)
+			}
+			walkTypeComments(childPath, childNode, child, f)
+		}
+	}
+}
+
+// nestedNodes returns the schema of a nested type, using the same keys as model.TypeComments. If the node is a
+// combination of other schemas, such as a nullable type, the nested type is looked up in each of them.
+func nestedNodes(node map[string]any, key string) []map[string]any {
+	for _, combinator := range []string{"oneOf", "anyOf"} {
+		if branches, ok := node[combinator].([]any); ok {
+			out := []map[string]any{}
+			for _, branch := range branches {
+				if branchNode, ok := branch.(map[string]any); ok {
+					out = append(out, nestedNodes(branchNode, key)...)
+				}
+			}
+
+			return out
+		}
+	}
+
+	if properties, ok := node["properties"].(map[string]any); ok {
+		if child, ok := properties[key].(map[string]any); ok {
+			return []map[string]any{child}
+		}
+
+		return nil
+	}
+	if key == "" {
+		// the elements of a list or set, or the values of a map.
+		for _, keyword := range []string{"items", "additionalProperties"} {
+			if child, ok := node[keyword].(map[string]any); ok {
+				return []map[string]any{child}
+			}
+		}
+	}
+	// tuples use "prefixItems" in 2020-12, and the array form of "items" in draft-07.
+	items, ok := node["prefixItems"].([]any)
+	if !ok {
+		items, ok = node["items"].([]any)
+	}
+	if ok {
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(items) {
+			if child, ok := items[i].(map[string]any); ok {
+				return []map[string]any{child}
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseAnnotations returns the keywords set by all the @schema lines in a comment. Later lines override earlier ones.
+func parseAnnotations(comments []string) (map[string]any, error) {
+	keywords := make(map[string]any)
+	var errs []error
+	for _, line := range comments {
+		rest, ok := reader.IsAnnotation(line)
+		if !ok {
+			continue
+		}
+		lineKeywords, err := parseAnnotationLine(rest)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+		maps.Copy(keywords, lineKeywords)
+	}
+
+	return keywords, errors.Join(errs...)
+}
+
+// parseAnnotationLine parses a list of key=value pairs separated by spaces. Each value is parsed as JSON if possible,
+// and used as a string otherwise, so that format=email and format="email" are the same. A key without a value is
+// set to true.
+func parseAnnotationLine(s string) (map[string]any, error) {
+	keywords := make(map[string]any)
+	s = strings.TrimSpace(s)
+	for s != "" {
+		end := strings.IndexFunc(s, func(r rune) bool { return r == '=' || unicode.IsSpace(r) })
+		if end == -1 || s[end] != '=' {
+			key, rest, _ := strings.Cut(s, " ")
+			keywords[key] = true
+			s = strings.TrimSpace(rest)
+
+			continue
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("%w: missing keyword before %q", ErrInvalidAnnotation, s)
+		}
+
+		key, rest := s[:end], s[end+1:]
+		n := valueLength(rest)
+		raw := rest[:n]
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			if strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "[") || strings.HasPrefix(raw, "{") {
+				return nil, fmt.Errorf("%w: value of %q is not valid JSON: %w", ErrInvalidAnnotation, key, err)
+			}
+			value = raw
+		}
+		keywords[key] = value
+		s = strings.TrimSpace(rest[n:])
+	}
+
+	return keywords, nil
+}
+
+// valueLength returns the length of the value at the start of s. Strings, arrays and objects end at their closing
+// character, and anything else ends at the next space.
+func valueLength(s string) int {
+	depth := 0
+	inString := false
+	escaped := false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && r == '\\':
+			escaped = true
+		case r == '"':
+			inString = !inString
+			if !inString && depth == 0 {
+				return i + 1
+			}
+		case inString:
+		case r == '[' || r == '{':
+			depth++
+		case r == ']' || r == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case depth == 0 && unicode.IsSpace(r):
+			return i
+		}
+	}
+
+	return len(s)
+}
@@ -0,0 +1,218 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// shapeKeywords are the keywords which describe an object type, rather than how it is used by a variable or
+// attribute. Only these keywords are moved to a shared definition.
+var shapeKeywords = []string{"type", "properties", "required", "additionalProperties", "nullable"}
+
+// deduplicateTypes moves the object types which appear more than once in the variables of a schema to shared
+// definitions. Two object types are the same if their schemas are the same, including any nested types, defaults of
+// optional attributes and annotations. Definitions which are already in the schema, such as the schemas of child
+// modules, are kept, and their names aren't reused. In OpenAPI 3.0, the definitions are returned so that they can be added as
+// other components, instead of being added to the schema.
+func deduplicateTypes(schema map[string]any, options CreateSchemaOptions) map[string]any {
+	if !options.DeduplicateTypes {
+		return nil
+	}
+	if options.Draft == OpenAPI30 && options.OpenAPIComponent == "" {
+		if !options.SuppressLogging {
+			fmt.Println("Warning: shared definitions in OpenAPI 3.0 must be components, types won't be deduplicated " +
+				"unless the schema is an OpenAPI component")
+		}
+
+		return nil
+	}
+	properties, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	keyword, refPrefix := options.Draft.definitionsKeyword()
+	d := &deduplicator{
+		refPrefix: refPrefix,
+		draft:     options.Draft,
+		counts:    make(map[string]int),
+		names:     make(map[string]string),
+		defs:      make(map[string]any),
+		usedNames: make(map[string]bool),
+	}
+	if options.OpenAPIComponent != "" {
+		d.usedNames[openAPIComponentKey(options.OpenAPIComponent)] = true
+	}
+	existing, _ := schema[keyword].(map[string]any)
+	for name := range existing {
+		d.usedNames[name] = true
+	}
+
+	// variables are visited in alphabetical order, so that the names of the definitions don't change between runs.
+	names := slices.Sorted(maps.Keys(properties))
+	for _, name := range names {
+		d.count(properties[name])
+	}
+	for _, name := range names {
+		properties[name] = d.replace(name, properties[name])
+	}
+
+	if len(d.defs) == 0 {
+		return nil
+	}
+	if keyword == "" {
+		return d.defs
+	}
+	if existing != nil {
+		maps.Copy(existing, d.defs)
+	} else {
+		schema[keyword] = d.defs
+	}
+
+	return nil
+}
+
+type deduplicator struct {
+	refPrefix string
+	draft     Draft
+	// counts is the number of times each object type appears, keyed by the JSON of its schema.
+	counts map[string]int
+	// names maps each object type which has been moved to a definition to the name of the definition.
+	names     map[string]string
+	defs      map[string]any
+	usedNames map[string]bool
+}
+
+// count adds the object types in a schema to the counts.
+func (d *deduplicator) count(node any) {
+	m, ok := node.(map[string]any)
+	if !ok {
+		return
+	}
+	if key, ok := shapeKey(m); ok {
+		d.counts[key]++
+	}
+	forEachChild(m, "", func(_ string, child any) any {
+		d.count(child)
+
+		return child
+	})
+}
+
+// replace returns a schema in which every object type which appears more than once is replaced with a reference to
+// its definition. Name is the name of the variable or attribute the schema belongs to, which is used to name the
+// definitions.
+func (d *deduplicator) replace(name string, node any) any {
+	m, ok := node.(map[string]any)
+	if !ok {
+		return node
+	}
+	// the key is found before the nested types are replaced, since that is how they were counted.
+	key, isObject := shapeKey(m)
+	forEachChild(m, name, d.replace)
+	if !isObject || d.counts[key] < 2 {
+		return m
+	}
+
+	defName, ok := d.names[key]
+	if !ok {
+		defName = d.newName(name)
+		d.names[key] = defName
+		shape := make(map[string]any)
+		for _, keyword := range shapeKeywords {
+			if value, ok := m[keyword]; ok {
+				shape[keyword] = value
+			}
+		}
+		d.defs[defName] = shape
+	}
+
+	rest := make(map[string]any)
+	for keyword, value := range m {
+		if !slices.Contains(shapeKeywords, keyword) {
+			rest[keyword] = value
+		}
+	}
+	ref := map[string]any{"$ref": d.refPrefix + defName}
+	if len(rest) == 0 {
+		return ref
+	}
+	// other keywords next to "$ref" are ignored before 2020-12, so the reference is wrapped in "allOf" instead.
+	if d.draft == Draft202012 {
+		rest["$ref"] = ref["$ref"]
+	} else {
+		rest["allOf"] = []any{ref}
+	}
+
+	return rest
+}
+
+// newName returns a name for a definition which hasn't been used yet, based on the name of the variable or
+// attribute which uses it first.
+func (d *deduplicator) newName(name string) string {
+	candidate := name
+	for i := 2; d.usedNames[candidate]; i++ {
+		candidate = name + "_" + strconv.Itoa(i)
+	}
+	d.usedNames[candidate] = true
+
+	return candidate
+}
+
+// shapeKey returns a key which is the same for two object types if their schemas are the same. It returns false if
+// the schema isn't an object type.
+func shapeKey(node map[string]any) (string, bool) {
+	if _, ok := node["properties"].(map[string]any); !ok || !isObjectType(node["type"]) {
+		return "", false
+	}
+	shape := make(map[string]any)
+	for _, keyword := range shapeKeywords {
+		if value, ok := node[keyword]; ok {
+			shape[keyword] = value
+		}
+	}
+	// map keys are sorted when marshalled, so the same schema always has the same key.
+	key, err := json.Marshal(shape)
+	if err != nil {
+		return "", false
+	}
+
+	return string(key), true
+}
+
+// forEachChild calls f on each schema nested in a node, and replaces it with the result. The name passed to f is
+// the name of the attribute for object properties, and the name of the node itself for the elements of lists, sets,
+// maps and tuples.
+func forEachChild(node map[string]any, name string, f func(name string, child any) any) {
+	if properties, ok := node["properties"].(map[string]any); ok {
+		for _, key := range slices.Sorted(maps.Keys(properties)) {
+			properties[key] = f(key, properties[key])
+		}
+	}
+	for _, keyword := range []string{"items", "additionalProperties"} {
+		if child, ok := node[keyword].(map[string]any); ok {
+			node[keyword] = f(name, child)
+		}
+	}
+	for _, keyword := range []string{"items", "prefixItems", "anyOf", "oneOf"} {
+		if children, ok := node[keyword].([]any); ok {
+			for i, child := range children {
+				children[i] = f(name, child)
+			}
+		}
+	}
+}
+
+// isObjectType returns true if the "type" keyword of a schema is "object", or a list of types containing "object"
+// in the type-array nullable style.
+func isObjectType(t any) bool {
+	if types, ok := t.([]any); ok {
+		return slices.Contains(types, "object")
+	}
+
+	return t == "object"
+}
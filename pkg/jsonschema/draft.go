@@ -0,0 +1,94 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package jsonschema
+
+import (
+	"fmt"
+)
+
+// Draft is the version of JSON Schema which the schema is written for.
+type Draft string
+
+const (
+	// Draft07 is JSON Schema draft-07. This is the default if no draft is set.
+	Draft07 Draft = "draft-07"
+	// Draft202012 is JSON Schema 2020-12, as used by OpenAPI 3.1. Tuples use "prefixItems" instead of the array form
+	// of "items".
+	Draft202012 Draft = "2020-12"
+	// OpenAPI30 is the schema object of OpenAPI 3.0, which is a subset of JSON Schema with its own keywords. Nullable
+	// types use "nullable": true instead of a "null" type, there is no "$schema" keyword, and tuples are
+	// approximated as lists.
+	OpenAPI30 Draft = "openapi-3.0"
+)
+
+var ErrUnknownDraft = fmt.Errorf("unknown draft, must be one of %q, %q or %q", Draft07, Draft202012, OpenAPI30)
+
+// ParseDraft returns the draft with the given name. An empty name is the same as Draft07.
+func ParseDraft(name string) (Draft, error) {
+	switch Draft(name) {
+	case "", Draft07:
+		return Draft07, nil
+	case Draft202012:
+		return Draft202012, nil
+	case OpenAPI30:
+		return OpenAPI30, nil
+	default:
+		return "", fmt.Errorf("%q: %w", name, ErrUnknownDraft)
+	}
+}
+
+// schemaURI returns the value of the "$schema" keyword for the draft, or an empty string if the keyword isn't used.
+func (d Draft) schemaURI() string {
+	switch d {
+	case Draft202012:
+		return "https://json-schema.org/draft/2020-12/schema"
+	case OpenAPI30:
+		return ""
+	default:
+		return "http://json-schema.org/draft-07/schema#"
+	}
+}
+
+// setSchemaURI sets the "$schema" keyword of a root schema, if the draft uses it.
+func (d Draft) setSchemaURI(schema map[string]any) {
+	if uri := d.schemaURI(); uri != "" {
+		schema["$schema"] = uri
+	}
+}
+
+// definitionsKeyword returns the keyword which holds the shared definitions of a schema, and the prefix of a "$ref"
+// to one of them. This is "$defs" in draft-07 as well as 2020-12, which is where the schemas of child modules are
+// too, so that a schema only has one place for definitions. OpenAPI 3.0 doesn't allow definitions inside a schema,
+// so the keyword is empty, and they are stored as other components of the specification instead.
+func (d Draft) definitionsKeyword() (string, string) {
+	if d == OpenAPI30 {
+		return "", "#/components/schemas/"
+	}
+
+	return "$defs", "#/$defs/"
+}
+
+// errorMessageKeyword returns the keyword which holds the error messages of validation rules. OpenAPI 3.0 only
+// allows extension keywords starting with "x-" to be added to a schema, so "x-errorMessage" is used instead of the
+// "errorMessage" keyword of ajv-errors.
+func (d Draft) errorMessageKeyword() string {
+	if d == OpenAPI30 {
+		return "x-errorMessage"
+	}
+
+	return "errorMessage"
+}
+
+// setTupleItems sets the schemas of the elements of a tuple. In draft-07, these are an array in "items". In
+// 2020-12, they are in "prefixItems", and "items": false stops any more elements from being added. OpenAPI 3.0
+// only allows a single schema in "items", so each element may be any of the element types.
+func (d Draft) setTupleItems(node map[string]any, items []any) {
+	switch d {
+	case Draft202012:
+		node["prefixItems"] = items
+		node["items"] = false
+	case OpenAPI30:
+		node["items"] = approximateTupleItems(items)
+	default:
+		node["items"] = items
+	}
+}
@@ -4,6 +4,8 @@ package jsonschema
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"path/filepath"
 	"slices"
 
 	"github.com/HewlettPackard/terraschema/pkg/model"
@@ -17,27 +19,186 @@ type CreateSchemaOptions struct {
 	DebugOut                  bool
 	SuppressLogging           bool
 	NullableAll               bool
-	IgnoreVariables           []string
-	RootProperties            map[string]string
+	// IgnoreVariables leaves these variables out of the schema. Names only apply to the root module, and the
+	// variables of child modules are given by their address, e.g. "module.net.cidr".
+	IgnoreVariables []string
+	RootProperties  map[string]string
+	// ChildModules follows module blocks with a local source path and adds a schema for each child module
+	// under "$defs", keyed by the module's address (e.g. "module.net").
+	ChildModules bool
+	// ModuleManifest adds a schema for each module installed by 'terraform init' under "$defs", using the directories
+	// recorded in .terraform/modules/modules.json. Unlike ChildModules, this includes modules from registries and
+	// other remote sources, and nothing is downloaded. If both are set, ModuleManifest is used and ChildModules is
+	// ignored.
+	ModuleManifest bool
+	// ExcludeEphemeral leaves ephemeral variables out of the schema. They are still set in the same way as other
+	// variables, but some tools only need the variables which are stored in the plan and state.
+	ExcludeEphemeral bool
+	// TerraformMetadata adds an "x-terraform" keyword to the root of the schema, with the versions of Terraform and
+	// the providers required by the module, and the type of backend it uses, as declared in its terraform blocks.
+	TerraformMetadata bool
+	// Draft is the version of JSON Schema to write the schema for. The default is Draft07. Child module schemas are
+	// always added under "$defs", which is the name used by 2020-12 and is also understood by draft-07 validators.
+	Draft Draft
+	// NullableStyle is the way nullable types are written in the schema. The default is NullableOneOfTitled. It is
+	// ignored in OpenAPI 3.0, which uses "nullable": true.
+	NullableStyle NullableStyle
+	// NullableNested allows null for object attributes, map values and the elements of lists, sets and tuples, which
+	// Terraform accepts in the same way as it does for variables. A null optional attribute is set to its default.
+	// It applies even if the variable itself isn't nullable.
+	NullableNested bool
+	// OpenAPIComponent wraps the schema in a document of the form {"components": {"schemas": {"<name>": <schema>}}},
+	// using this as the name, so that it can be merged into an OpenAPI specification. It is normally used with
+	// OpenAPI30.
+	OpenAPIComponent string
+	// DeduplicateTypes moves object types which appear more than once in the variables to shared definitions, and
+	// replaces each copy with a "$ref". The definitions are named after the first variable or attribute which uses
+	// them. They are stored in "$defs" along with any child module schemas, and as other components in OpenAPI 3.0,
+	// which requires OpenAPIComponent.
+	DeduplicateTypes bool
+	// Dialect decides which files are read, and which arguments are allowed in variable blocks. The default is
+	// reader.DialectTerraform.
+	Dialect reader.Dialect
+	// CollectErrors reads every file in the module and reports all the problems found together, instead of
+	// stopping at the first one.
+	CollectErrors bool
+	// BestEffort creates a schema from the variables which could be read, even if there are problems with the
+	// module. It implies CollectErrors. The schema is returned along with an error wrapping ErrPartialSchema.
+	BestEffort bool
+	// ErrorMessages adds the error_message of each validation block to an "errorMessage" keyword, which maps each
+	// keyword produced by the validation block to its message. This format is compatible with ajv-errors.
+	ErrorMessages bool
+	// SensitivePasswordFormat adds "format": "password" to sensitive string variables, so that form generators
+	// such as react-jsonschema-form render a masked input.
+	SensitivePasswordFormat bool
+	// IncludeSensitiveDefaults keeps the default value of sensitive variables in the schema. By default, they are
+	// redacted so that secrets in the module aren't published with the schema.
+	IncludeSensitiveDefaults bool
+	// SourceLocations adds an "x-terraform-source" keyword to each variable, with the file and lines where it is
+	// declared in the module.
+	SourceLocations bool
+	// CommentDescriptions uses the comment above a variable block as its description, if it doesn't have a
+	// description argument. Comments above the attributes of object types become the description of the attribute.
+	CommentDescriptions bool
+	// DeclarationOrder adds an "x-order" keyword to each variable, with its position in the module. JSON objects are
+	// unordered, so this allows form generators to show the variables in the order they are declared.
+	DeclarationOrder bool
 }
 
+// ErrPartialSchema is returned alongside the schema when BestEffort is set and some variables couldn't be read.
+var ErrPartialSchema = errors.New("schema only contains the variables which could be read")
+
 func CreateSchema(path string, options CreateSchemaOptions) (map[string]any, error) {
+	return CreateSchemaFS(reader.LocalFS{}, filepath.ToSlash(path), options)
+}
+
+// CreateSchemaFS is the same as CreateSchema, but reads the module in the directory dir of fsys. Child modules are
+// only followed if they are inside fsys.
+func CreateSchemaFS(fsys fs.FS, dir string, options CreateSchemaOptions) (map[string]any, error) {
 	schemaOut := make(map[string]any)
 
-	varMap, err := reader.GetVarMap(path, options.DebugOut)
-	if err != nil {
+	varMap, err := getVarMap(fsys, dir, options)
+	// in best effort mode, a partial var map is used to create the schema and the error is returned at the end.
+	var readErr error
+	if err != nil && options.BestEffort && varMap != nil {
+		readErr = fmt.Errorf("%w: %w", ErrPartialSchema, err)
+	} else if err != nil {
 		if options.AllowEmpty && (errors.Is(err, reader.ErrFilesNotFound) || errors.Is(err, reader.ErrNoVariablesFound)) {
 			if !options.SuppressLogging {
-				fmt.Printf("Warning: directory %q: %v, creating empty schema file\n", path, err)
+				fmt.Printf("Warning: directory %q: %v, creating empty schema file\n", dir, err)
 			}
 
 			return schemaOut, nil
 		} else {
-			return schemaOut, fmt.Errorf("error reading tf files at %q: %w", path, err)
+			return schemaOut, fmt.Errorf("error reading tf files at %q: %w", dir, err)
+		}
+	}
+
+	schemaOut, err = createSchemaFromVarMap(varMap, options)
+	if err != nil {
+		return schemaOut, err
+	}
+	options.Draft.setSchemaURI(schemaOut)
+
+	// the schemas of child modules are added first, so that the shared definitions of types don't replace them.
+	err = addModuleKeywords(schemaOut, fsys, dir, options)
+	if err != nil {
+		return schemaOut, err
+	}
+	components := deduplicateTypes(schemaOut, options)
+
+	return finishSchema(schemaOut, components, options), readErr
+}
+
+// finishSchema adds the custom root properties to a schema, and wraps it in an OpenAPI document if needed, along
+// with any other components it refers to.
+func finishSchema(schemaOut map[string]any, components map[string]any, options CreateSchemaOptions) map[string]any {
+	// Add  the custom properties in last to allow overriding the default properties.
+	for key, value := range options.RootProperties {
+		schemaOut[key] = value
+	}
+
+	if options.OpenAPIComponent != "" {
+		return wrapOpenAPIComponent(options.OpenAPIComponent, schemaOut, components)
+	}
+
+	return schemaOut
+}
+
+// addModuleKeywords adds the keywords to the root of the schema which describe the module as a whole, rather than
+// its variables: the Terraform metadata and the schemas of child modules.
+func addModuleKeywords(schemaOut map[string]any, fsys fs.FS, dir string, options CreateSchemaOptions) error {
+	if options.TerraformMetadata {
+		metadata, err := getTerraformMetadata(fsys, dir, options)
+		if err != nil {
+			return err
+		}
+		if len(metadata) != 0 {
+			schemaOut["x-terraform"] = metadata
 		}
 	}
 
-	schemaOut["$schema"] = "http://json-schema.org/draft-07/schema#"
+	if !options.ChildModules && !options.ModuleManifest {
+		return nil
+	}
+	// schemas in OpenAPI 3.0 can only refer to other components, rather than containing their own definitions.
+	if options.Draft == OpenAPI30 {
+		if !options.SuppressLogging {
+			fmt.Println("Warning: child module schemas are not supported in OpenAPI 3.0, they will be ignored")
+		}
+
+		return nil
+	}
+
+	var defs map[string]any
+	var err error
+	if options.ModuleManifest {
+		defs, err = getManifestModuleSchemas(fsys, dir, options)
+	} else {
+		defs, err = getChildModuleSchemas(fsys, dir, "", []string{dir}, options)
+	}
+	if err != nil {
+		return err
+	}
+	if len(defs) != 0 {
+		schemaOut["$defs"] = defs
+	}
+
+	return nil
+}
+
+func getVarMap(fsys fs.FS, dir string, options CreateSchemaOptions) (map[string]model.TranslatedVariable, error) {
+	return reader.GetVarMapFS(fsys, dir, reader.GetVarMapOptions{
+		DebugOut:            options.DebugOut,
+		ContinueOnError:     options.CollectErrors || options.BestEffort,
+		Dialect:             options.Dialect,
+		CommentDescriptions: options.CommentDescriptions,
+	})
+}
+
+// createSchemaFromVarMap creates the object schema describing the variables of a single module.
+func createSchemaFromVarMap(varMap map[string]model.TranslatedVariable, options CreateSchemaOptions) (map[string]any, error) {
+	schemaOut := make(map[string]any)
 	schemaOut["type"] = "object"
 	schemaOut["additionalProperties"] = options.AllowAdditionalProperties
 
@@ -47,6 +208,9 @@ func CreateSchema(path string, options CreateSchemaOptions) (map[string]any, err
 		if slices.Contains(options.IgnoreVariables, name) {
 			continue
 		}
+		if options.ExcludeEphemeral && variable.Variable.IsEphemeral() {
+			continue
+		}
 		if variable.Required && !options.RequireAll {
 			requiredArray = append(requiredArray, name)
 		}
@@ -66,20 +230,20 @@ func CreateSchema(path string, options CreateSchemaOptions) (map[string]any, err
 	slices.SortFunc(requiredArray, sortInterfaceAlphabetical) // get required in alphabetical order
 	schemaOut["required"] = requiredArray
 
-	// Add  the custom properties in last to allow overriding the default properties.
-	for key, value := range options.RootProperties {
-		schemaOut[key] = value
-	}
-
 	return schemaOut, nil
 }
 
 //nolint:cyclop
 func createNode(name string, v model.TranslatedVariable, options CreateSchemaOptions) (map[string]any, error) {
-	tc, err := reader.GetTypeConstraint(v.Variable.Type)
+	tc, defaults, err := reader.GetTypeConstraintWithDefaults(v.Variable.Type)
 	if err != nil {
 		return nil, fmt.Errorf("getting type constraint for %q: %w", name, err)
 	}
+	// the defaults of optional attributes are part of the default value of a sensitive variable, so they are
+	// redacted in the same way.
+	if v.Variable.IsSensitive() && !options.IncludeSensitiveDefaults {
+		defaults = nil
+	}
 
 	// The default value for nullable is the value of NullableAll. For the purpose of keeping the JSON Schema relatively
 	// clean, this is normally set to false. Setting the default value to true is consistent with Terraform behavior.
@@ -88,12 +252,12 @@ func createNode(name string, v model.TranslatedVariable, options CreateSchemaOpt
 		nullableTranslatedValue = *v.Variable.Nullable
 	}
 
-	node, err := getNodeFromType(name, tc, nullableTranslatedValue, options)
+	node, err := getNodeFromType(name, tc, defaults, nullableTranslatedValue, options)
 	if err != nil {
 		return nil, fmt.Errorf("%q: %w", name, err)
 	}
 
-	if v.Variable.Default != nil {
+	if v.Variable.Default != nil && (!v.Variable.IsSensitive() || options.IncludeSensitiveDefaults) {
 		def, err := reader.ExpressionToJSONObject(v.Variable.Default)
 		if err != nil {
 			return nil, fmt.Errorf("error converting default value to JSON object: %w", err)
@@ -103,7 +267,10 @@ func createNode(name string, v model.TranslatedVariable, options CreateSchemaOpt
 
 	// Apply all specified validation rules in the order specified in the HCL config.
 	for i, validation := range v.Variable.Validations {
-		err = parseConditionToNode(validation.Condition, v.ConditionsAsString[i], name, &node)
+		keywords, err := parseConditionToNode(validation.Condition, v.ConditionsAsString[i], name, &node)
+		if err == nil && options.ErrorMessages && i < len(v.ErrorMessagesAsString) {
+			addErrorMessage(node, keywords, v.ErrorMessagesAsString[i], options.Draft)
+		}
 		// if an error occurs, log it and continue.
 		if err != nil && !options.SuppressLogging {
 			fmt.Printf("Warning: couldn't apply validation for %q with condition %q: %v\n",
@@ -123,19 +290,106 @@ func createNode(name string, v model.TranslatedVariable, options CreateSchemaOpt
 		}
 	}
 
+	if options.Draft == OpenAPI30 {
+		convertOpenAPIKeywords(node)
+	}
+
 	if v.Variable.Description != nil {
 		node["description"] = *v.Variable.Description
 	}
 
-	// if nullable is true, then we need to unset the definition for "type" here, since it was only added to
-	// satisfy the validation rules and is not actually a part of the schema.
+	if v.Variable.IsSensitive() {
+		applySensitive(node, options)
+	}
+
+	if v.Variable.IsEphemeral() {
+		node["x-terraform-ephemeral"] = true
+	}
+
+	if v.Variable.Deprecated != nil {
+		node["deprecated"] = true
+		node["x-deprecation-message"] = *v.Variable.Deprecated
+	}
+
+	if options.SourceLocations {
+		node["x-terraform-source"] = map[string]any{
+			"file":       v.Location.Filename,
+			"start_line": float64(v.Location.StartLine),
+			"end_line":   float64(v.Location.EndLine),
+		}
+	}
+
+	if options.DeclarationOrder {
+		node["x-order"] = float64(v.Order)
+	}
+
+	// if nullable is true, then the definition for "type" was only added to satisfy the validation rules, and needs to
+	// be replaced here.
 	if nullableTranslatedValue {
-		delete(node, "type")
+		finishNullableNode(node, options)
 	}
 
+	// annotations are applied last, so that they can override anything set by terraschema.
+	applyCommentAnnotations(name, node, v, options)
+
 	return node, nil
 }
 
+// applySensitive marks a node as sensitive. "writeOnly" is the closest JSON Schema equivalent to Terraform's
+// sensitive values, since they can be set but aren't shown in the output of Terraform.
+func applySensitive(node map[string]any, options CreateSchemaOptions) {
+	node["writeOnly"] = true
+	node["x-terraform-sensitive"] = true
+	// the type is still set here for nullable variables, since it is only removed after the validation rules apply.
+	if options.SensitivePasswordFormat && node["type"] == "string" {
+		typeBranchOf(node)["format"] = "password"
+	}
+}
+
+// typeBranchOf returns the branch of a nullable node's "oneOf" or "anyOf" which isn't the "null" type. Form generators
+// only read keywords such as "format" from the branch they render, so they are added there in the same way as the
+// title in getNullableNode. Other nodes, including the generic type which has a branch for every type, are returned
+// as they are.
+func typeBranchOf(node map[string]any) map[string]any {
+	for _, combinator := range []string{"oneOf", "anyOf"} {
+		branches, _ := node[combinator].([]any)
+		var typeBranches []map[string]any
+		for _, branch := range branches {
+			if branchNode, ok := branch.(map[string]any); ok && branchNode["type"] != "null" {
+				typeBranches = append(typeBranches, branchNode)
+			}
+		}
+		if len(typeBranches) == 1 {
+			return typeBranches[0]
+		}
+	}
+
+	return node
+}
+
+// addErrorMessage maps each of the keywords produced by a validation rule to its error message, in the format used by
+// ajv-errors: {"errorMessage": {"<keyword>": "<message>"}}. The "type" keyword isn't included, since it isn't
+// produced by the validation rule itself. In OpenAPI 3.0, the keyword is "x-errorMessage".
+func addErrorMessage(node map[string]any, keywords []string, message string, draft Draft) {
+	if message == "" {
+		return
+	}
+	keyword := draft.errorMessageKeyword()
+	errorMessages, ok := node[keyword].(map[string]any)
+	if !ok {
+		errorMessages = make(map[string]any)
+	}
+	for _, validationKeyword := range keywords {
+		if validationKeyword == "type" {
+			continue
+		}
+		errorMessages[validationKeyword] = message
+	}
+	if len(errorMessages) != 0 {
+		node[keyword] = errorMessages
+	}
+}
+
 func sortInterfaceAlphabetical(a, b any) int {
 	aString, ok := a.(string)
 	if !ok {
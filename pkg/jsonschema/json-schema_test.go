@@ -8,10 +8,13 @@ import (
 	"path/filepath"
 	"slices"
 	"testing"
+	"testing/fstest"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/stretchr/testify/require"
+
+	"github.com/HewlettPackard/terraschema/pkg/reader"
 )
 
 func TestCreateSchema(t *testing.T) {
@@ -25,6 +28,19 @@ func TestCreateSchema(t *testing.T) {
 		"complex-types",
 		"custom-validation",
 		"ignore-variables",
+		"child-modules",
+		"json-syntax",
+		"override-files",
+		"sensitive",
+		"optional-defaults",
+		"module-manifest",
+		"tofu",
+		"ephemeral",
+		"outputs",
+		"terraform-metadata",
+		"annotations",
+		"unused-variables",
+		"repeated-types",
 	}
 	for i := range testCases {
 		name := testCases[i]
@@ -64,6 +80,19 @@ func TestCreateSchemaWithRootProperties(t *testing.T) {
 		"complex-types",
 		"custom-validation",
 		"ignore-variables",
+		"child-modules",
+		"json-syntax",
+		"override-files",
+		"sensitive",
+		"optional-defaults",
+		"module-manifest",
+		"tofu",
+		"ephemeral",
+		"outputs",
+		"terraform-metadata",
+		"annotations",
+		"unused-variables",
+		"repeated-types",
 	}
 	for i := range testCases {
 		name := testCases[i]
@@ -96,6 +125,431 @@ func TestCreateSchemaWithRootProperties(t *testing.T) {
 	}
 }
 
+// TestCreateSchemaWithOptions compares the schema created with each option that has its own expected file. Every case
+// allows additional properties, which is the default of the CLI.
+func TestCreateSchemaWithOptions(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name         string
+		expectedFile string
+		options      CreateSchemaOptions
+	}{
+		{"child-modules", "schema-child-modules.json", CreateSchemaOptions{ChildModules: true}},
+		{"module-manifest", "schema-module-manifest.json", CreateSchemaOptions{ModuleManifest: true}},
+		{"tofu", "schema-tofu.json", CreateSchemaOptions{Dialect: reader.DialectOpenTofu}},
+		{"ephemeral", "schema-exclude-ephemeral.json", CreateSchemaOptions{ExcludeEphemeral: true}},
+		{"terraform-metadata", "schema-terraform-metadata.json", CreateSchemaOptions{TerraformMetadata: true}},
+		{"annotations", "schema-comment-descriptions.json", CreateSchemaOptions{CommentDescriptions: true}},
+		{"custom-validation", "schema-error-messages.json", CreateSchemaOptions{ErrorMessages: true}},
+		{"simple-types", "schema-2020-12.json", CreateSchemaOptions{Draft: Draft202012}},
+		{"complex-types", "schema-2020-12.json", CreateSchemaOptions{Draft: Draft202012}},
+		{"simple-types", "schema-openapi.json", CreateSchemaOptions{
+			Draft:          OpenAPI30,
+			NullableAll:    true,
+			NullableNested: true,
+		}},
+		{"custom-validation", "schema-openapi.json", CreateSchemaOptions{Draft: OpenAPI30}},
+		{"custom-validation", "schema-openapi-error-messages.json", CreateSchemaOptions{
+			Draft:         OpenAPI30,
+			ErrorMessages: true,
+		}},
+		{"simple", "schema-openapi-component.json", CreateSchemaOptions{Draft: OpenAPI30, OpenAPIComponent: "simple"}},
+		{"repeated-types", "schema-deduplicate-types.json", CreateSchemaOptions{DeduplicateTypes: true}},
+		{"repeated-types", "schema-deduplicate-types-2020-12.json", CreateSchemaOptions{
+			DeduplicateTypes: true,
+			Draft:            Draft202012,
+		}},
+		{"simple-types", "schema-nullable-any-of.json", CreateSchemaOptions{
+			NullableAll:    true,
+			NullableNested: true,
+			NullableStyle:  NullableAnyOf,
+		}},
+		{"simple-types", "schema-nullable-type-array.json", CreateSchemaOptions{
+			NullableAll:    true,
+			NullableNested: true,
+			NullableStyle:  NullableTypeArray,
+		}},
+		{"complex-types", "schema-nullable-any-of.json", CreateSchemaOptions{
+			NullableAll:    true,
+			NullableNested: true,
+			NullableStyle:  NullableAnyOf,
+		}},
+		{"complex-types", "schema-nullable-type-array.json", CreateSchemaOptions{
+			NullableAll:    true,
+			NullableNested: true,
+			NullableStyle:  NullableTypeArray,
+		}},
+		{"custom-validation", "schema-nullable-any-of.json", CreateSchemaOptions{
+			NullableAll:    true,
+			NullableNested: true,
+			NullableStyle:  NullableAnyOf,
+		}},
+		{"custom-validation", "schema-nullable-type-array.json", CreateSchemaOptions{
+			NullableAll:    true,
+			NullableNested: true,
+			NullableStyle:  NullableTypeArray,
+		}},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name+"/"+tc.expectedFile, func(t *testing.T) {
+			t.Parallel()
+			expected, err := os.ReadFile(filepath.Join("../../test/expected", tc.name, tc.expectedFile))
+			require.NoError(t, err)
+
+			tc.options.AllowAdditionalProperties = true
+			tc.options.SuppressLogging = true
+			result, err := CreateSchema(filepath.Join("../../test/modules", tc.name), tc.options)
+			require.NoError(t, err)
+
+			var expectedMap map[string]any
+			err = json.Unmarshal(expected, &expectedMap)
+			require.NoError(t, err)
+
+			if d := cmp.Diff(expectedMap, result); d != "" {
+				t.Errorf("Schema has incorrect value (-want,+got):\n%s", d)
+			}
+			if tc.options.Draft == OpenAPI30 {
+				// OpenAPI schema objects don't declare a dialect.
+				require.NotContains(t, result, "$schema")
+			}
+		})
+	}
+}
+
+func TestCreateSchemaWithOptions_Errors(t *testing.T) {
+	t.Parallel()
+	_, err := CreateSchema("../../test/modules/child-modules", CreateSchemaOptions{ModuleManifest: true})
+	require.ErrorIs(t, err, reader.ErrManifestNotFound)
+
+	_, err = ParseDraft("draft-04")
+	require.ErrorIs(t, err, ErrUnknownDraft)
+
+	_, err = ParseNullableStyle("oneOf")
+	require.ErrorIs(t, err, ErrUnknownNullableStyle)
+}
+
+func TestCreateSchemaWithTerraformMetadata_NoTerraformBlock(t *testing.T) {
+	t.Parallel()
+	// modules without a terraform block don't get the keyword at all.
+	result, err := CreateSchema("../../test/modules/custom-validation", CreateSchemaOptions{TerraformMetadata: true})
+	require.NoError(t, err)
+	require.NotContains(t, result, "x-terraform")
+}
+
+func TestApproximateTupleItems(t *testing.T) {
+	t.Parallel()
+	stringNode := map[string]any{"type": "string"}
+	numberNode := map[string]any{"type": "number"}
+
+	require.Equal(t, stringNode, approximateTupleItems([]any{stringNode, map[string]any{"type": "string"}}))
+	require.Equal(t, map[string]any{"anyOf": []any{stringNode, numberNode}},
+		approximateTupleItems([]any{stringNode, numberNode, stringNode}))
+}
+
+func TestCreateSchemaWithDeduplicateTypes_ChildModules(t *testing.T) {
+	t.Parallel()
+	object := "object({\n    host = string\n  })"
+	fsys := fstest.MapFS{
+		"main.tf": &fstest.MapFile{
+			Data: []byte("variable \"primary\" {\n  type = " + object + "\n}\n\nvariable \"replica\" {\n  type = " +
+				object + "\n}\n\nmodule \"net\" {\n  source = \"./net\"\n}\n"),
+		},
+		"net/variables.tf": &fstest.MapFile{Data: []byte("variable \"cidr\" {\n  type = string\n}\n")},
+	}
+
+	// the shared definitions and the child module schemas are in the same keyword, in every draft.
+	for _, draft := range []Draft{Draft07, Draft202012} {
+		result, err := CreateSchemaFS(fsys, ".", CreateSchemaOptions{
+			ChildModules:     true,
+			DeduplicateTypes: true,
+			Draft:            draft,
+		})
+		require.NoError(t, err)
+		require.NotContains(t, result, "definitions")
+
+		defs, ok := result["$defs"].(map[string]any)
+		require.True(t, ok)
+		require.Len(t, defs, 2)
+		require.Contains(t, defs, "module.net")
+		require.Contains(t, defs, "primary")
+	}
+
+	// definitions which are already in the schema aren't replaced.
+	schema := map[string]any{
+		"properties": map[string]any{
+			"primary": map[string]any{"type": "object", "properties": map[string]any{}},
+			"replica": map[string]any{"type": "object", "properties": map[string]any{}},
+		},
+		"$defs": map[string]any{"primary": map[string]any{"type": "string"}},
+	}
+	deduplicateTypes(schema, CreateSchemaOptions{DeduplicateTypes: true})
+	require.Equal(t, map[string]any{
+		"primary":   map[string]any{"type": "string"},
+		"primary_2": map[string]any{"type": "object", "properties": map[string]any{}},
+	}, schema["$defs"])
+	require.Equal(t, map[string]any{"$ref": "#/$defs/primary_2"}, schema["properties"].(map[string]any)["primary"])
+}
+
+func TestCreateSchemaWithDeduplicateTypes_OpenAPI(t *testing.T) {
+	t.Parallel()
+	result, err := CreateSchema("../../test/modules/repeated-types", CreateSchemaOptions{
+		DeduplicateTypes: true,
+		Draft:            OpenAPI30,
+		OpenAPIComponent: "admins",
+		SuppressLogging:  true,
+	})
+	require.NoError(t, err)
+
+	// the definitions are other components, and their names don't clash with the module's component.
+	components, ok := result["components"].(map[string]any)
+	require.True(t, ok)
+	schemas, ok := components["schemas"].(map[string]any)
+	require.True(t, ok)
+	require.Len(t, schemas, 4)
+	require.Contains(t, schemas, "admins_2")
+	users, ok := schemas["admins"].(map[string]any)["properties"].(map[string]any)["users"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"$ref": "#/components/schemas/admins_2"}, users["items"])
+}
+
+func TestCreateOutputSchema(t *testing.T) {
+	t.Parallel()
+	tfPath := "../../test/modules/outputs"
+	expected, err := os.ReadFile("../../test/expected/outputs/schema-outputs.json")
+	require.NoError(t, err)
+
+	result, err := CreateOutputSchema(tfPath, CreateSchemaOptions{AllowAdditionalProperties: true})
+	require.NoError(t, err)
+
+	var expectedMap map[string]any
+	err = json.Unmarshal(expected, &expectedMap)
+	require.NoError(t, err)
+
+	if d := cmp.Diff(expectedMap, result); d != "" {
+		t.Errorf("Schema has incorrect value (-want,+got):\n%s", d)
+	}
+
+	_, err = CreateOutputSchema("../../test/modules/custom-validation", CreateSchemaOptions{})
+	require.ErrorIs(t, err, reader.ErrNoOutputsFound)
+}
+
+func TestCreateSchemaFS(t *testing.T) {
+	t.Parallel()
+	expected, err := os.ReadFile("../../test/expected/child-modules/schema-child-modules.json")
+	require.NoError(t, err)
+
+	result, err := CreateSchemaFS(os.DirFS("../../test/modules"), "child-modules", CreateSchemaOptions{
+		AllowAdditionalProperties: true,
+		ChildModules:              true,
+	})
+	require.NoError(t, err)
+
+	var expectedMap map[string]any
+	err = json.Unmarshal(expected, &expectedMap)
+	require.NoError(t, err)
+
+	if d := cmp.Diff(expectedMap, result); d != "" {
+		t.Errorf("Schema has incorrect value (-want,+got):\n%s", d)
+	}
+}
+
+func TestCreateSchemaWithChildModules_IgnoreVariables(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"main.tf": &fstest.MapFile{
+			Data: []byte("variable \"cidr\" {\n  type = string\n}\n\nmodule \"net\" {\n  source = \"./net\"\n}\n"),
+		},
+		"net/variables.tf": &fstest.MapFile{
+			Data: []byte("variable \"cidr\" {\n  type = string\n}\n\nvariable \"region\" {\n  type = string\n}\n"),
+		},
+	}
+
+	// a plain name only ignores the variable of the root module, and child module variables are ignored by address.
+	result, err := CreateSchemaFS(fsys, ".", CreateSchemaOptions{
+		ChildModules:    true,
+		IgnoreVariables: []string{"cidr", "module.net.region"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, result["properties"])
+
+	defs, ok := result["$defs"].(map[string]any)
+	require.True(t, ok)
+	net, ok := defs["module.net"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"cidr": map[string]any{"type": "string"}}, net["properties"])
+}
+
+func TestCreateSchemaFS_SourceOutsideFS(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"main.tf": &fstest.MapFile{
+			Data: []byte("variable \"a\" {\n  type = string\n}\n\nmodule \"shared\" {\n  source = \"../shared\"\n}\n"),
+		},
+	}
+
+	result, err := CreateSchemaFS(fsys, ".", CreateSchemaOptions{ChildModules: true, SuppressLogging: true})
+	require.NoError(t, err)
+	require.NotContains(t, result, "$defs")
+	require.Equal(t, map[string]any{"a": map[string]any{"type": "string"}}, result["properties"])
+}
+
+func TestCreateSchemaSensitiveOptions(t *testing.T) {
+	t.Parallel()
+	result, err := CreateSchema("../../test/modules/sensitive", CreateSchemaOptions{
+		SensitivePasswordFormat:  true,
+		IncludeSensitiveDefaults: true,
+	})
+	require.NoError(t, err)
+
+	properties, ok := result["properties"].(map[string]any)
+	require.True(t, ok)
+
+	password, ok := properties["password"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "password", password["format"])
+	require.Equal(t, "hunter2", password["default"])
+
+	// api_token is nullable, so the format is on the string branch, which is the one form generators render.
+	apiToken, ok := properties["api_token"].(map[string]any)
+	require.True(t, ok)
+	require.NotContains(t, apiToken, "format")
+	apiTokenBranches, ok := apiToken["oneOf"].([]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"title": "string", "type": "string", "format": "password"}, apiTokenBranches[1])
+
+	// in the type-array style, there are no branches.
+	result, err = CreateSchema("../../test/modules/sensitive", CreateSchemaOptions{
+		SensitivePasswordFormat: true,
+		NullableStyle:           NullableTypeArray,
+	})
+	require.NoError(t, err)
+	apiToken, ok = result["properties"].(map[string]any)["api_token"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "password", apiToken["format"])
+
+	credentials, ok := properties["credentials"].(map[string]any)
+	require.True(t, ok)
+	require.NotContains(t, credentials, "format")
+	require.Equal(t, map[string]any{"username": "admin", "password": "hunter2"}, credentials["default"])
+
+	notSensitive, ok := properties["not_sensitive"].(map[string]any)
+	require.True(t, ok)
+	require.NotContains(t, notSensitive, "format")
+
+	// the defaults of optional attributes are only included along with the default of the variable.
+	for _, include := range []bool{false, true} {
+		result, err := CreateSchema("../../test/modules/sensitive", CreateSchemaOptions{IncludeSensitiveDefaults: include})
+		require.NoError(t, err)
+
+		properties, ok := result["properties"].(map[string]any)
+		require.True(t, ok)
+		database, ok := properties["database"].(map[string]any)
+		require.True(t, ok)
+		databaseProperties, ok := database["properties"].(map[string]any)
+		require.True(t, ok)
+		password, ok := databaseProperties["password"].(map[string]any)
+		require.True(t, ok)
+		if include {
+			require.Equal(t, "hunter2", password["default"])
+		} else {
+			require.NotContains(t, password, "default")
+		}
+	}
+}
+
+func TestCreateSchemaWithSourceLocations(t *testing.T) {
+	t.Parallel()
+	result, err := CreateSchema("../../test/modules/simple", CreateSchemaOptions{SourceLocations: true})
+	require.NoError(t, err)
+
+	properties, ok := result["properties"].(map[string]any)
+	require.True(t, ok)
+	age, ok := properties["age"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{
+		"file":       "variables.tf",
+		"start_line": float64(9),
+		"end_line":   float64(12),
+	}, age["x-terraform-source"])
+}
+
+func TestCreateSchemaWithDeclarationOrder(t *testing.T) {
+	t.Parallel()
+	result, err := CreateSchema("../../test/modules/override-files", CreateSchemaOptions{DeclarationOrder: true})
+	require.NoError(t, err)
+
+	properties, ok := result["properties"].(map[string]any)
+	require.True(t, ok)
+	for name, order := range map[string]float64{"name": 0, "age": 1, "region": 2} {
+		node, ok := properties[name].(map[string]any)
+		require.True(t, ok)
+		require.Equal(t, order, node["x-order"], name)
+	}
+}
+
+func TestParseAnnotations(t *testing.T) {
+	t.Parallel()
+	keywords, err := parseAnnotations([]string{
+		"A comment which isn't an annotation.",
+		`@schema format=email title="Admin email" minimum=1`,
+		`@schema readOnly examples=["a b", "c"] title=Override`,
+		"@schemas format=uri",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"format":   "email",
+		"title":    "Override",
+		"minimum":  float64(1),
+		"readOnly": true,
+		"examples": []any{"a b", "c"},
+	}, keywords)
+
+	keywords, err = parseAnnotations([]string{`@schema title="unterminated`, "@schema format=email"})
+	require.ErrorIs(t, err, ErrInvalidAnnotation)
+	require.Equal(t, map[string]any{"format": "email"}, keywords)
+
+	_, err = parseAnnotations([]string{"@schema =email"})
+	require.ErrorIs(t, err, ErrInvalidAnnotation)
+}
+
+func TestCreateSchemaBestEffort(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "a.tf"), []byte("variable \"a\" {\n  type = string\n}\n"), 0o600)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "b.tf"), []byte("variable \"b\" {\n  type = \n}\n"), 0o600)
+	require.NoError(t, err)
+
+	_, err = CreateSchema(dir, CreateSchemaOptions{CollectErrors: true})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrPartialSchema)
+
+	result, err := CreateSchema(dir, CreateSchemaOptions{BestEffort: true})
+	require.ErrorIs(t, err, ErrPartialSchema)
+	require.Equal(t, map[string]any{"a": map[string]any{"type": "string"}}, result["properties"])
+	require.Equal(t, []any{"a"}, result["required"])
+}
+
+func TestCreateSchemaBestEffort_InvalidTypeAndDefault(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"a.tf": {Data: []byte("variable \"a\" {\n  type = string\n}\n\nvariable \"b\" {\n  type = strin\n}\n")},
+		"c.tf": {Data: []byte("variable \"c\" {\n  default = upper(\"c\")\n}\n\nvariable \"d\" {\n  type = bool\n}\n")},
+	}
+
+	// problems with the type or default of a variable don't stop the other variables from being used.
+	result, err := CreateSchemaFS(fsys, ".", CreateSchemaOptions{BestEffort: true})
+	require.ErrorIs(t, err, ErrPartialSchema)
+	var diagErr *reader.DiagnosticsError
+	require.ErrorAs(t, err, &diagErr)
+	require.Len(t, diagErr.Diagnostics, 2)
+	require.Equal(t, map[string]any{
+		"a": map[string]any{"type": "string"},
+		"d": map[string]any{"type": "boolean"},
+	}, result["properties"])
+}
+
 type errorLocation struct {
 	name            string
 	nestedLocations []errorLocation
@@ -355,6 +809,107 @@ func TestSampleInput(t *testing.T) {
 				},
 			},
 		},
+		// draft 2020-12
+		{
+			name:             "complex-types full input draft 2020-12",
+			filePath:         "../../test/expected/complex-types/sample-input/test-input-all.json",
+			schemaPath:       "../../test/expected/complex-types/schema-2020-12.json",
+			keywordLocations: nil,
+		},
+		{
+			name:       "complex-types bad input draft 2020-12",
+			filePath:   "../../test/expected/complex-types/sample-input/test-input-bad.json",
+			schemaPath: "../../test/expected/complex-types/schema-2020-12.json",
+			keywordLocations: []errorLocation{
+				{
+					name: "/properties/a_very_complicated_object",
+					nestedLocations: []errorLocation{
+						{name: "/properties/a_very_complicated_object/properties/a/type"},
+						{name: "/properties/a_very_complicated_object/properties/b/minItems"},
+						{name: "/properties/a_very_complicated_object/properties/c/additionalProperties/type"},
+						{
+							name: "/properties/a_very_complicated_object/properties/d",
+							nestedLocations: []errorLocation{
+								{
+									name: "/properties/a_very_complicated_object/properties/d/properties/a",
+									nestedLocations: []errorLocation{
+										{name: "/properties/a_very_complicated_object/properties/d/properties/a/items/items/type"},
+										{name: "/properties/a_very_complicated_object/properties/d/properties/a/items/type"},
+									},
+								},
+								{name: "/properties/a_very_complicated_object/properties/d/properties/b/type"},
+							},
+						},
+						{name: "/properties/a_very_complicated_object/properties/e/prefixItems/1/type"},
+						{
+							name: "/properties/a_very_complicated_object/properties/f",
+							nestedLocations: []errorLocation{
+								{name: "/properties/a_very_complicated_object/properties/f/items/items/type"},
+								{name: "/properties/a_very_complicated_object/properties/f/uniqueItems"},
+							},
+						},
+					},
+				},
+				{
+					name: "/properties/an_object_with_optional",
+					nestedLocations: []errorLocation{
+						{name: "/properties/an_object_with_optional/properties/a/type"},
+						{name: "/properties/an_object_with_optional/properties/b/type"},
+						{name: "/properties/an_object_with_optional/properties/d/type"},
+						{name: "/properties/an_object_with_optional/required"},
+					},
+				},
+			},
+		},
+		// shared definitions
+		{
+			name:             "repeated-types full input",
+			filePath:         "../../test/expected/repeated-types/sample-input/test-input-all.json",
+			schemaPath:       "../../test/expected/repeated-types/schema.json",
+			keywordLocations: nil,
+		},
+		{
+			name:             "repeated-types full input deduplicated",
+			filePath:         "../../test/expected/repeated-types/sample-input/test-input-all.json",
+			schemaPath:       "../../test/expected/repeated-types/schema-deduplicate-types.json",
+			keywordLocations: nil,
+		},
+		{
+			name:             "repeated-types full input deduplicated 2020-12",
+			filePath:         "../../test/expected/repeated-types/sample-input/test-input-all.json",
+			schemaPath:       "../../test/expected/repeated-types/schema-deduplicate-types-2020-12.json",
+			keywordLocations: nil,
+		},
+		{
+			name:       "repeated-types bad input deduplicated",
+			filePath:   "../../test/expected/repeated-types/sample-input/test-input-bad.json",
+			schemaPath: "../../test/expected/repeated-types/schema-deduplicate-types.json",
+			keywordLocations: []errorLocation{
+				{
+					name: "/properties/primary_database/allOf/0",
+					nestedLocations: []errorLocation{
+						{
+							name: "/properties/primary_database/allOf/0/$ref",
+							nestedLocations: []errorLocation{
+								{
+									name: "/properties/primary_database/allOf/0/$ref/properties/backup/$ref",
+									nestedLocations: []errorLocation{
+										{name: "/properties/primary_database/allOf/0/$ref/properties/backup/$ref/properties/enabled/type"},
+									},
+								},
+								{name: "/properties/primary_database/allOf/0/$ref/properties/version/type"},
+							},
+						},
+					},
+				},
+				{
+					name: "/properties/users/items/$ref",
+					nestedLocations: []errorLocation{
+						{name: "/properties/users/items/$ref/required"},
+					},
+				},
+			},
+		},
 		// null input on all fields, nullableAll is false
 		{
 			name:       "simple null input nullableAll false",
@@ -508,6 +1063,30 @@ func TestSampleInput(t *testing.T) {
 			schemaPath:       "../../test/expected/complex-types/schema-nullable-all.json",
 			keywordLocations: nil,
 		},
+		// null nested attributes and elements, which Terraform accepts but are only allowed with nested nullability
+		{
+			name:       "complex-types nested null input",
+			filePath:   "../../test/expected/complex-types/sample-input/test-input-nested-null.json",
+			schemaPath: "../../test/expected/complex-types/schema.json",
+			keywordLocations: []errorLocation{
+				{name: "/properties/a_very_complicated_object", nestedLocations: []errorLocation{
+					{name: "/properties/a_very_complicated_object/properties/a/type"},
+					{name: "/properties/a_very_complicated_object/properties/b", nestedLocations: []errorLocation{
+						{name: "/properties/a_very_complicated_object/properties/b/items/0/items/type"},
+						{name: "/properties/a_very_complicated_object/properties/b/items/1/type"},
+					}},
+					{name: "/properties/a_very_complicated_object/properties/c/additionalProperties/type"},
+					{name: "/properties/a_very_complicated_object/properties/e/items/1/type"},
+				}},
+				{name: "/properties/an_object_with_optional/properties/d/type"},
+			},
+		},
+		{
+			name:             "complex-types nested null input nullableAll true",
+			filePath:         "../../test/expected/complex-types/sample-input/test-input-nested-null.json",
+			schemaPath:       "../../test/expected/complex-types/schema-nullable-all.json",
+			keywordLocations: nil,
+		},
 		{
 			// of note: custom validation still applies to nullable fields, and sometimes 'null' doesn't satisfy the
 			// condition, meaning these fields effectively can't be null.
@@ -522,6 +1101,52 @@ func TestSampleInput(t *testing.T) {
 				{name: "/properties/a_string_enum_kind_2/enum"},
 			},
 		},
+		{
+			name:             "simple-types null input nullable style anyOf",
+			filePath:         "../../test/expected/simple-types/sample-input/test-input-null.json",
+			schemaPath:       "../../test/expected/simple-types/schema-nullable-any-of.json",
+			keywordLocations: nil,
+		},
+		{
+			name:             "complex-types null input nullable style anyOf",
+			filePath:         "../../test/expected/complex-types/sample-input/test-input-null.json",
+			schemaPath:       "../../test/expected/complex-types/schema-nullable-any-of.json",
+			keywordLocations: nil,
+		},
+		{
+			name:       "custom-validation null input nullable style anyOf",
+			filePath:   "../../test/expected/custom-validation/sample-input/test-input-null.json",
+			schemaPath: "../../test/expected/custom-validation/schema-nullable-any-of.json",
+			keywordLocations: []errorLocation{
+				{name: "/properties/a_number_enum_kind_1/enum"},
+				{name: "/properties/a_number_enum_kind_2/enum"},
+				{name: "/properties/a_string_enum_kind_1/enum"},
+				{name: "/properties/a_string_enum_kind_2/enum"},
+			},
+		},
+		{
+			name:             "simple-types null input nullable style type-array",
+			filePath:         "../../test/expected/simple-types/sample-input/test-input-null.json",
+			schemaPath:       "../../test/expected/simple-types/schema-nullable-type-array.json",
+			keywordLocations: nil,
+		},
+		{
+			name:             "complex-types null input nullable style type-array",
+			filePath:         "../../test/expected/complex-types/sample-input/test-input-null.json",
+			schemaPath:       "../../test/expected/complex-types/schema-nullable-type-array.json",
+			keywordLocations: nil,
+		},
+		{
+			name:       "custom-validation null input nullable style type-array",
+			filePath:   "../../test/expected/custom-validation/sample-input/test-input-null.json",
+			schemaPath: "../../test/expected/custom-validation/schema-nullable-type-array.json",
+			keywordLocations: []errorLocation{
+				{name: "/properties/a_number_enum_kind_1/enum"},
+				{name: "/properties/a_number_enum_kind_2/enum"},
+				{name: "/properties/a_string_enum_kind_1/enum"},
+				{name: "/properties/a_string_enum_kind_2/enum"},
+			},
+		},
 	}
 	for i := range testCases {
 		tc := testCases[i]
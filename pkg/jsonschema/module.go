@@ -0,0 +1,162 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+	"github.com/HewlettPackard/terraschema/pkg/reader"
+)
+
+// getChildModuleSchemas creates a schema for every module called from the module in dir which has a local source,
+// and then recursively for the modules called by those. The schemas are returned in a flat map keyed by module
+// address, e.g. "module.net" or "module.net.module.subnet". Stack holds the directories which are currently being
+// visited, so that a module which (indirectly) calls itself doesn't cause infinite recursion.
+func getChildModuleSchemas(
+	fsys fs.FS,
+	dir string,
+	parentAddress string,
+	stack []string,
+	options CreateSchemaOptions,
+) (map[string]any, error) {
+	calls, err := reader.GetModuleCallsFS(fsys, dir, options.Dialect)
+	if err != nil {
+		if errors.Is(err, reader.ErrFilesNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error reading module calls at %q: %w", dir, err)
+	}
+
+	defs := make(map[string]any)
+	// iterate in a fixed order so that debug output is deterministic.
+	for _, name := range slices.Sorted(maps.Keys(calls)) {
+		call := calls[name]
+		address := parentAddress + "module." + name
+		if !reader.IsLocalSource(call.Source) {
+			if options.DebugOut {
+				fmt.Printf("Debug: skipping %s, source %q is not a local path\n", address, call.Source)
+			}
+
+			continue
+		}
+
+		childPath, ok := reader.ResolveSource(fsys, dir, call.Source)
+		if !ok {
+			if !options.SuppressLogging {
+				fmt.Printf("Warning: skipping %s, source %q is outside of the file system\n", address, call.Source)
+			}
+
+			continue
+		}
+		if slices.ContainsFunc(stack, func(p string) bool { return sameDirectory(p, childPath) }) {
+			if !options.SuppressLogging {
+				fmt.Printf("Warning: skipping %s, source %q creates a cycle\n", address, call.Source)
+			}
+
+			continue
+		}
+
+		childSchema, err := createChildModuleSchema(fsys, childPath, address, options)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", address, err)
+		}
+		defs[address] = childSchema
+
+		childStack := append(slices.Clone(stack), childPath)
+		childDefs, err := getChildModuleSchemas(fsys, childPath, address+".", childStack, options)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(defs, childDefs)
+	}
+
+	return defs, nil
+}
+
+// getManifestModuleSchemas creates a schema for every module recorded in the module manifest of the root module in
+// dir. The schemas are returned in a flat map keyed by module address, in the same way as getChildModuleSchemas.
+func getManifestModuleSchemas(fsys fs.FS, dir string, options CreateSchemaOptions) (map[string]any, error) {
+	records, err := reader.GetModuleManifestFS(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := make(map[string]any)
+	for _, record := range records {
+		address := reader.ModuleAddress(record.Key)
+		childPath, ok := reader.ResolveManifestDir(fsys, dir, record)
+		if !ok {
+			if !options.SuppressLogging {
+				fmt.Printf("Warning: skipping %s, directory %q is outside of the file system\n", address, record.Dir)
+			}
+
+			continue
+		}
+		if info, err := fs.Stat(fsys, childPath); err != nil || !info.IsDir() {
+			if !options.SuppressLogging {
+				fmt.Printf("Warning: skipping %s, directory %q not found, run 'terraform init' again\n", address, record.Dir)
+			}
+
+			continue
+		}
+		if options.DebugOut {
+			fmt.Printf("Debug: found %s with source %q in %q\n", address, record.Source, record.Dir)
+		}
+
+		childSchema, err := createChildModuleSchema(fsys, childPath, address, options)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", address, err)
+		}
+		defs[address] = childSchema
+	}
+
+	return defs, nil
+}
+
+// createChildModuleSchema creates the schema for a single child module. Unlike the root module, a child module
+// without any variables is not an error, since it's common for modules to not take any input. BestEffort only
+// applies to the root module, so any other problem reading a child module is always an error.
+func createChildModuleSchema(fsys fs.FS, dir string, address string, options CreateSchemaOptions) (map[string]any, error) {
+	options.IgnoreVariables = childIgnoreVariables(address, options.IgnoreVariables)
+	varMap, err := getVarMap(fsys, dir, options)
+	if err != nil {
+		if !errors.Is(err, reader.ErrFilesNotFound) && !errors.Is(err, reader.ErrNoVariablesFound) {
+			return nil, fmt.Errorf("error reading tf files at %q: %w", dir, err)
+		}
+		varMap = map[string]model.TranslatedVariable{}
+	}
+
+	return createSchemaFromVarMap(varMap, options)
+}
+
+// childIgnoreVariables returns the names of the variables to ignore in the child module at address. A plain name in
+// IgnoreVariables only applies to the root module, and a variable in a child module is ignored by its address, e.g.
+// "module.net.cidr".
+func childIgnoreVariables(address string, ignoreVariables []string) []string {
+	names := []string{}
+	for _, ignored := range ignoreVariables {
+		// variable names can't contain a ".", so anything left after the address belongs to a nested module.
+		if name, ok := strings.CutPrefix(ignored, address+"."); ok && !strings.Contains(name, ".") {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func sameDirectory(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+
+	return absA == absB
+}
@@ -0,0 +1,58 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package jsonschema
+
+import (
+	"fmt"
+)
+
+// NullableStyle is the way a type which can also be null is written in the schema.
+type NullableStyle string
+
+const (
+	// NullableOneOfTitled uses "oneOf" with a "null" type and the type itself, each with a title. The titles are
+	// shown by form generators such as react-jsonschema-form to select a type. This is the default if no style is
+	// set.
+	NullableOneOfTitled NullableStyle = "oneOf-titled"
+	// NullableAnyOf uses "anyOf" with a "null" type and the type itself, without any titles.
+	NullableAnyOf NullableStyle = "anyOf"
+	// NullableTypeArray adds "null" to the "type" keyword, e.g. "type": ["string", "null"].
+	NullableTypeArray NullableStyle = "type-array"
+)
+
+var ErrUnknownNullableStyle = fmt.Errorf("unknown nullable style, must be one of %q, %q or %q",
+	NullableOneOfTitled, NullableAnyOf, NullableTypeArray)
+
+// ParseNullableStyle returns the nullable style with the given name. An empty name is the same as
+// NullableOneOfTitled.
+func ParseNullableStyle(name string) (NullableStyle, error) {
+	switch NullableStyle(name) {
+	case "", NullableOneOfTitled:
+		return NullableOneOfTitled, nil
+	case NullableAnyOf:
+		return NullableAnyOf, nil
+	case NullableTypeArray:
+		return NullableTypeArray, nil
+	default:
+		return "", fmt.Errorf("%q: %w", name, ErrUnknownNullableStyle)
+	}
+}
+
+// titled returns true if the branches of "oneOf" and "anyOf" have titles in this style.
+func (s NullableStyle) titled() bool {
+	return s != NullableAnyOf
+}
+
+// finishNullableNode sets the final "type" keyword of a nullable node. While validation rules are applied, "type" is
+// the name of the type which isn't null, since the rules depend on it. In the "oneOf" and "anyOf" styles, it is then
+// removed, since the branches contain the types. In OpenAPI 3.0, it stays alongside "nullable": true.
+func finishNullableNode(node map[string]any, options CreateSchemaOptions) {
+	switch {
+	case options.Draft == OpenAPI30:
+	case options.NullableStyle == NullableTypeArray:
+		if t, ok := node["type"].(string); ok {
+			node["type"] = []any{t, "null"}
+		}
+	default:
+		delete(node, "type")
+	}
+}
@@ -0,0 +1,72 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package jsonschema
+
+import (
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+)
+
+// invalidComponentKeyChars matches the characters which aren't allowed in the key of a component in OpenAPI 3.0.
+var invalidComponentKeyChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// approximateTupleItems returns a single schema for the elements of a tuple, for OpenAPI 3.0 which doesn't support
+// tuples. If all the elements have the same schema, it is used directly. Otherwise, each element may be any of them.
+// The number of elements is still checked with "minItems" and "maxItems".
+func approximateTupleItems(items []any) map[string]any {
+	distinct := []any{}
+	for _, item := range items {
+		if !slices.ContainsFunc(distinct, func(other any) bool { return reflect.DeepEqual(item, other) }) {
+			distinct = append(distinct, item)
+		}
+	}
+	if len(distinct) == 1 {
+		if node, ok := distinct[0].(map[string]any); ok {
+			return node
+		}
+	}
+
+	return map[string]any{"anyOf": distinct}
+}
+
+// convertOpenAPIKeywords replaces the keywords created by validation rules which have a different meaning in
+// OpenAPI 3.0. In JSON Schema, "exclusiveMinimum" and "exclusiveMaximum" are numbers, but in OpenAPI 3.0 they are
+// booleans which modify "minimum" and "maximum".
+func convertOpenAPIKeywords(node map[string]any) {
+	for exclusive, inclusive := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+		limit, ok := node[exclusive].(float64)
+		if !ok {
+			continue
+		}
+		delete(node, exclusive)
+		// an inclusive limit which is stricter than the exclusive one makes it redundant.
+		if current, ok := node[inclusive].(float64); ok {
+			if (inclusive == "minimum" && current > limit) || (inclusive == "maximum" && current < limit) {
+				continue
+			}
+		}
+		node[inclusive] = limit
+		node[exclusive] = true
+	}
+}
+
+// wrapOpenAPIComponent returns a document containing the schema as an entry in "components.schemas" of an OpenAPI
+// specification, so that it can be merged into an existing specification. Other components, such as the shared
+// definitions the schema refers to, are added alongside it. Characters which aren't allowed in the key of a
+// component are replaced with underscores.
+func wrapOpenAPIComponent(name string, schema map[string]any, components map[string]any) map[string]any {
+	schemas := map[string]any{}
+	maps.Copy(schemas, components)
+	schemas[openAPIComponentKey(name)] = schema
+
+	return map[string]any{
+		"components": map[string]any{
+			"schemas": schemas,
+		},
+	}
+}
+
+func openAPIComponentKey(name string) string {
+	return invalidComponentKeyChars.ReplaceAllString(name, "_")
+}
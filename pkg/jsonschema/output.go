@@ -0,0 +1,151 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+	"github.com/HewlettPackard/terraschema/pkg/reader"
+)
+
+// CreateOutputSchema creates a schema for the document printed by 'terraform output -json' for the module at path.
+// Each output is an object with the fields "sensitive", "type" and "value". The schema for the value is the type of
+// the variable it refers to if it is a direct reference such as var.name, or the type of the value if it is a
+// literal. Otherwise, any value is allowed. Outputs are only required if RequireAll is set, since Terraform leaves
+// out outputs with a null value.
+func CreateOutputSchema(path string, options CreateSchemaOptions) (map[string]any, error) {
+	return CreateOutputSchemaFS(reader.LocalFS{}, filepath.ToSlash(path), options)
+}
+
+// CreateOutputSchemaFS is the same as CreateOutputSchema, but reads the module in the directory dir of fsys.
+func CreateOutputSchemaFS(fsys fs.FS, dir string, options CreateSchemaOptions) (map[string]any, error) {
+	schemaOut := make(map[string]any)
+
+	outputMap, err := reader.GetOutputMapFS(fsys, dir, options.Dialect)
+	if err != nil {
+		if options.AllowEmpty && (errors.Is(err, reader.ErrFilesNotFound) || errors.Is(err, reader.ErrNoOutputsFound)) {
+			if !options.SuppressLogging {
+				fmt.Printf("Warning: directory %q: %v, creating empty schema file\n", dir, err)
+			}
+
+			return schemaOut, nil
+		} else {
+			return schemaOut, fmt.Errorf("error reading tf files at %q: %w", dir, err)
+		}
+	}
+
+	// the variables are only needed to find the type of outputs which refer to them, so a module without any
+	// variables is fine.
+	varMap, err := getVarMap(fsys, dir, options)
+	if err != nil && !errors.Is(err, reader.ErrNoVariablesFound) {
+		return schemaOut, fmt.Errorf("error reading tf files at %q: %w", dir, err)
+	}
+
+	properties := make(map[string]any)
+	requiredArray := []any{}
+	for name, output := range outputMap {
+		if options.RequireAll {
+			requiredArray = append(requiredArray, name)
+		}
+		node, err := createOutputNode(name, output, varMap, options)
+		if err != nil {
+			return schemaOut, fmt.Errorf("error creating node for output %q: %w", name, err)
+		}
+		properties[name] = node
+	}
+	slices.SortFunc(requiredArray, sortInterfaceAlphabetical)
+
+	options.Draft.setSchemaURI(schemaOut)
+	schemaOut["type"] = "object"
+	schemaOut["additionalProperties"] = options.AllowAdditionalProperties
+	schemaOut["properties"] = properties
+	schemaOut["required"] = requiredArray
+
+	return finishSchema(schemaOut, nil, options), nil
+}
+
+// createOutputNode creates the schema for a single output in the output of 'terraform output -json', e.g.
+// {"sensitive": false, "type": "string", "value": "example"}. The "type" field is the type of the value in the JSON
+// format used by Terraform, which is allowed to be anything.
+func createOutputNode(
+	name string,
+	o model.TranslatedOutput,
+	varMap map[string]model.TranslatedVariable,
+	options CreateSchemaOptions,
+) (map[string]any, error) {
+	valueNode, err := createOutputValueNode(name, o, varMap, options)
+	if err != nil {
+		return nil, err
+	}
+
+	sensitiveNode := map[string]any{
+		"type":  "boolean",
+		"const": o.Output.IsSensitive(),
+	}
+	// OpenAPI 3.0 doesn't have "const", but an enum with a single value is the same.
+	if options.Draft == OpenAPI30 {
+		delete(sensitiveNode, "const")
+		sensitiveNode["enum"] = []any{o.Output.IsSensitive()}
+	}
+
+	node := map[string]any{
+		"type":                 "object",
+		"additionalProperties": options.AllowAdditionalProperties,
+		"properties": map[string]any{
+			"sensitive": sensitiveNode,
+			"type":      map[string]any{},
+			"value":     valueNode,
+		},
+		"required": []any{"sensitive", "type", "value"},
+	}
+	if o.Output.Description != nil {
+		node["description"] = *o.Output.Description
+	}
+
+	return node, nil
+}
+
+func createOutputValueNode(
+	name string,
+	o model.TranslatedOutput,
+	varMap map[string]model.TranslatedVariable,
+	options CreateSchemaOptions,
+) (map[string]any, error) {
+	if v, ok := varMap[o.VariableReference]; ok && o.VariableReference != "" {
+		tc, defaults, err := reader.GetTypeConstraintWithDefaults(v.Variable.Type)
+		if err != nil {
+			return nil, fmt.Errorf("getting type constraint for %q: %w", o.VariableReference, err)
+		}
+		if v.Variable.IsSensitive() && !options.IncludeSensitiveDefaults {
+			defaults = nil
+		}
+		nullable := options.NullableAll
+		if v.Variable.Nullable != nil {
+			nullable = *v.Variable.Nullable
+		}
+		node, err := getNodeFromType(name, tc, defaults, nullable, options)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", name, err)
+		}
+		// the type is only set on nullable nodes so that validation rules can be applied, see createNode.
+		if nullable {
+			finishNullableNode(node, options)
+		}
+
+		return node, nil
+	}
+
+	if t, ok := reader.GetLiteralValueType(o.Output.Value); ok {
+		return getNodeFromType(name, t, nil, false, options)
+	}
+
+	if options.DebugOut {
+		fmt.Printf("Debug: the type of output %q can't be inferred from %q, any value is allowed\n", name, o.ValueAsString)
+	}
+
+	return map[string]any{}, nil
+}
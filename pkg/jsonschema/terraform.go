@@ -0,0 +1,45 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package jsonschema
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/HewlettPackard/terraschema/pkg/reader"
+)
+
+// getTerraformMetadata returns the value of the "x-terraform" keyword, which describes the versions of Terraform and
+// the providers required by the module in dir, and the type of backend it uses. Settings which aren't declared in the
+// module are left out, so the result is empty if the module doesn't have a terraform block.
+func getTerraformMetadata(fsys fs.FS, dir string, options CreateSchemaOptions) (map[string]any, error) {
+	settings, err := reader.GetTerraformSettingsFS(fsys, dir, options.Dialect)
+	if err != nil {
+		return nil, fmt.Errorf("error reading terraform blocks at %q: %w", dir, err)
+	}
+
+	metadata := make(map[string]any)
+	if len(settings.RequiredVersion) != 0 {
+		// Terraform uses the same syntax to combine version constraints in a single string.
+		metadata["required_version"] = strings.Join(settings.RequiredVersion, ", ")
+	}
+	if len(settings.RequiredProviders) != 0 {
+		providers := make(map[string]any)
+		for name, provider := range settings.RequiredProviders {
+			providerNode := make(map[string]any)
+			if provider.Source != "" {
+				providerNode["source"] = provider.Source
+			}
+			if provider.Version != "" {
+				providerNode["version"] = provider.Version
+			}
+			providers[name] = providerNode
+		}
+		metadata["required_providers"] = providers
+	}
+	if settings.Backend != "" {
+		metadata["backend"] = settings.Backend
+	}
+
+	return metadata, nil
+}
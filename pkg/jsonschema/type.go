@@ -4,6 +4,9 @@ package jsonschema
 import (
 	"fmt"
 	"slices"
+	"strconv"
+
+	"github.com/HewlettPackard/terraschema/pkg/reader"
 )
 
 var simpleTypeMap = map[string]string{
@@ -17,7 +20,13 @@ var genericTypesList = []string{
 	"dynamic",
 }
 
-func getNodeFromType(name string, typeInterface any, nullable bool, options CreateSchemaOptions) (map[string]any, error) {
+func getNodeFromType(
+	name string,
+	typeInterface any,
+	defaults *reader.TypeDefaults,
+	nullable bool,
+	options CreateSchemaOptions,
+) (map[string]any, error) {
 	// manage the generic case (any, dynamic, ...) first (handles nullable by itself)
 	if t, ok := typeInterface.(string); ok && slices.Contains(genericTypesList, t) {
 		return getGenericNode(name, typeInterface, nullable, options)
@@ -25,7 +34,7 @@ func getNodeFromType(name string, typeInterface any, nullable bool, options Crea
 
 	// handle nullable globally for other types
 	if nullable {
-		return getNullableNode(name, typeInterface, options)
+		return getNullableNode(name, typeInterface, defaults, options)
 	}
 
 	// get other types as non-nullable
@@ -37,7 +46,7 @@ func getNodeFromType(name string, typeInterface any, nullable bool, options Crea
 			return nil, fmt.Errorf("unsupported type %q", t)
 		}
 	case []any:
-		return getNodeFromSlice(t, options)
+		return getNodeFromSlice(t, defaults, options)
 	default:
 		return nil, fmt.Errorf("unsupported type for %#v", typeInterface)
 	}
@@ -54,67 +63,94 @@ func getGenericNode(name string, typeInterface any, nullable bool, options Creat
 	isSubtype := len(name) == 0
 	nullSupported := nullable || isSubtype
 
+	// in the type-array style, the generic type is a list of all the types instead.
+	if options.NullableStyle == NullableTypeArray && options.Draft != OpenAPI30 {
+		types := []any{"object", "array", "string", "number", "boolean"}
+		if nullSupported {
+			types = append(types, "null")
+		}
+		node["type"] = types
+		node["additionalProperties"] = options.AllowAdditionalProperties
+
+		return node, nil
+	}
+
 	// "anyOf" type to compose multiple types (as we want generic to support any type)
-	anyOfNode := []any{
-		map[string]any{
-			"type":                 "object",
-			"title":                "object",
-			"additionalProperties": options.AllowAdditionalProperties,
-		},
-		map[string]any{
-			"type":  "array",
-			"title": "array",
-		},
-		map[string]any{
-			"type":  "string",
-			"title": "string",
-		},
-		map[string]any{
-			"type":  "number",
-			"title": "number",
-		},
-		map[string]any{
-			"type":  "boolean",
-			"title": "boolean",
-		},
-	}
-	if nullSupported {
-		anyOfNode = append(anyOfNode, map[string]any{
-			"type":  "null",
-			"title": "null",
-		})
+	objectBranch := typeBranch("object", options)
+	objectBranch["additionalProperties"] = options.AllowAdditionalProperties
+	anyOfNode := []any{objectBranch}
+	for _, t := range []string{"array", "string", "number", "boolean"} {
+		anyOfNode = append(anyOfNode, typeBranch(t, options))
+	}
+	if nullSupported && options.Draft == OpenAPI30 {
+		// OpenAPI 3.0 has no "null" type, and "nullable" only applies alongside "type" in the same schema.
+		for _, branch := range anyOfNode {
+			if branchNode, ok := branch.(map[string]any); ok {
+				branchNode["nullable"] = true
+			}
+		}
+	} else if nullSupported {
+		anyOfNode = append(anyOfNode, typeBranch("null", options))
 	}
 	node["anyOf"] = anyOfNode
 
 	// write title only for plain type, not for subtype
-	if !isSubtype {
+	if !isSubtype && options.NullableStyle.titled() {
 		node["title"] = fmt.Sprintf("%s: Select a type", name)
 	}
 
 	return node, nil
 }
 
-func getNullableNode(name string, typeInterface any, options CreateSchemaOptions) (map[string]any, error) {
+// typeBranch returns a branch of "oneOf" or "anyOf" which only allows one type, with a title if the nullable style
+// uses them.
+func typeBranch(t string, options CreateSchemaOptions) map[string]any {
+	branch := map[string]any{"type": t}
+	if options.NullableStyle.titled() {
+		branch["title"] = t
+	}
+
+	return branch
+}
+
+func getNullableNode(
+	name string,
+	typeInterface any,
+	defaults *reader.TypeDefaults,
+	options CreateSchemaOptions,
+) (map[string]any, error) {
 	node := make(map[string]any)
 	if typeInterface == nil {
 		return node, nil
 	}
-	internalNode, err := getNodeFromType(name, typeInterface, false, options)
+	internalNode, err := getNodeFromType(name, typeInterface, defaults, false, options)
 	if err != nil {
 		return nil, err
 	}
+	if options.Draft == OpenAPI30 {
+		internalNode["nullable"] = true
+
+		return internalNode, nil
+	}
 	title, ok := internalNode["type"].(string)
 	if !ok {
 		return nil, fmt.Errorf("could not get type %v as a string", internalNode["type"])
 	}
 
-	internalNode["title"] = title
-
-	node["oneOf"] = []any{
-		map[string]any{"type": "null", "title": "null"},
-		internalNode,
+	switch options.NullableStyle {
+	case NullableTypeArray:
+		// the type is changed to include "null" after the validation rules are applied, see finishNullableNode.
+		return internalNode, nil
+	case NullableAnyOf:
+		node["anyOf"] = []any{typeBranch("null", options), internalNode}
+	default:
+		internalNode["title"] = title
+		node["oneOf"] = []any{typeBranch("null", options), internalNode}
+		// nested nodes have no name, so they don't have a title, in the same way as in getGenericNode.
+		if name != "" {
+			node["title"] = fmt.Sprintf("%s: Select a type", name)
+		}
 	}
-	node["title"] = fmt.Sprintf("%s: Select a type", name)
 
 	// this is here until the validation rules are applied, because otherwise they error since "type" is undefined.
 	// This sets validation to apply to the top level, which implies that validation must pass even if the value is null.
@@ -123,29 +159,46 @@ func getNullableNode(name string, typeInterface any, options CreateSchemaOptions
 	return node, nil
 }
 
-func getNodeFromSlice(in []any, options CreateSchemaOptions) (map[string]any, error) {
+// getNestedNode creates the node for an object attribute, a map value or an element of a list, set or tuple. These
+// are nullable if NullableNested is set, since Terraform accepts null for them even if the variable isn't nullable.
+func getNestedNode(typeInterface any, defaults *reader.TypeDefaults, options CreateSchemaOptions) (map[string]any, error) {
+	node, err := getNodeFromType("", typeInterface, defaults, options.NullableNested, options)
+	if err != nil {
+		return nil, err
+	}
+	// validation rules are only applied to variables, so the type can be finished straight away.
+	if options.NullableNested {
+		finishNullableNode(node, options)
+	}
+
+	return node, nil
+}
+
+// getNodeFromSlice creates a node for a complex type. Defaults contains the default values of any optional object
+// attributes nested in the type, which are added to the "default" keyword of the corresponding node.
+func getNodeFromSlice(in []any, defaults *reader.TypeDefaults, options CreateSchemaOptions) (map[string]any, error) {
 	switch in[0] {
 	// "object" affects additionalProperties, properties, type and required
 	case "object":
-		return getObject(in, options)
+		return getObject(in, defaults, options)
 	// "map" affects additionalProperties and type.
 	case "map":
-		return getMap(in, options)
+		return getMap(in, defaults, options)
 	// "list" affects items, type
 	case "list":
-		return getList(in, options)
+		return getList(in, defaults, options)
 	// "set" affects items, type, uniqueItems
 	case "set":
-		return getSet(in, options)
+		return getSet(in, defaults, options)
 	// "tuple" affects items, type, maxItems, minItems
 	case "tuple":
-		return getTuple(in, options)
+		return getTuple(in, defaults, options)
 	default:
 		panic("unknown type")
 	}
 }
 
-func getObject(in []any, options CreateSchemaOptions) (map[string]any, error) {
+func getObject(in []any, defaults *reader.TypeDefaults, options CreateSchemaOptions) (map[string]any, error) {
 	node := map[string]any{
 		"type": "object",
 	}
@@ -185,10 +238,13 @@ func getObject(in []any, options CreateSchemaOptions) (map[string]any, error) {
 	properties := make(map[string]any)
 
 	for key, val := range inMap {
-		newNode, err := getNodeFromType("", val, false, options)
+		newNode, err := getNestedNode(val, defaults.Child(key), options)
 		if err != nil {
 			return nil, fmt.Errorf("object property %q: %w", key, err)
 		}
+		if def, ok := defaults.DefaultValue(key); ok {
+			newNode["default"] = def
+		}
 		properties[key] = newNode
 		// if the variable of the sub-object is marked as optional but RequireAll is true, then it is required.
 		if !optionals[key] || options.RequireAll {
@@ -204,14 +260,14 @@ func getObject(in []any, options CreateSchemaOptions) (map[string]any, error) {
 	return node, nil
 }
 
-func getMap(in []any, options CreateSchemaOptions) (map[string]any, error) {
+func getMap(in []any, defaults *reader.TypeDefaults, options CreateSchemaOptions) (map[string]any, error) {
 	node := map[string]any{
 		"type": "object",
 	}
 	if len(in) != 2 {
 		return nil, fmt.Errorf("map type must have exactly one additional element, %v", in)
 	}
-	newNode, err := getNodeFromType("", in[1], false, options)
+	newNode, err := getNestedNode(in[1], defaults.Child(""), options)
 	if err != nil {
 		return nil, fmt.Errorf("map: %w", err)
 	}
@@ -220,7 +276,7 @@ func getMap(in []any, options CreateSchemaOptions) (map[string]any, error) {
 	return node, nil
 }
 
-func getList(in []any, options CreateSchemaOptions) (map[string]any, error) {
+func getList(in []any, defaults *reader.TypeDefaults, options CreateSchemaOptions) (map[string]any, error) {
 	node := map[string]any{
 		"type": "array",
 	}
@@ -228,7 +284,7 @@ func getList(in []any, options CreateSchemaOptions) (map[string]any, error) {
 		return nil, fmt.Errorf("list type must have exactly one additional element, %v", in)
 	}
 
-	newNode, err := getNodeFromType("", in[1], false, options)
+	newNode, err := getNestedNode(in[1], defaults.Child(""), options)
 	if err != nil {
 		return nil, fmt.Errorf("list: %w", err)
 	}
@@ -237,7 +293,7 @@ func getList(in []any, options CreateSchemaOptions) (map[string]any, error) {
 	return node, nil
 }
 
-func getSet(in []any, options CreateSchemaOptions) (map[string]any, error) {
+func getSet(in []any, defaults *reader.TypeDefaults, options CreateSchemaOptions) (map[string]any, error) {
 	node := map[string]any{
 		"type":        "array",
 		"uniqueItems": true,
@@ -246,7 +302,7 @@ func getSet(in []any, options CreateSchemaOptions) (map[string]any, error) {
 		return nil, fmt.Errorf("set type must have exactly one additional element, %v", in)
 	}
 
-	newNode, err := getNodeFromType("", in[1], false, options)
+	newNode, err := getNestedNode(in[1], defaults.Child(""), options)
 	if err != nil {
 		return nil, fmt.Errorf("set: %w", err)
 	}
@@ -255,7 +311,7 @@ func getSet(in []any, options CreateSchemaOptions) (map[string]any, error) {
 	return node, nil
 }
 
-func getTuple(in []any, options CreateSchemaOptions) (map[string]any, error) {
+func getTuple(in []any, defaults *reader.TypeDefaults, options CreateSchemaOptions) (map[string]any, error) {
 	node := map[string]any{
 		"type": "array",
 	}
@@ -269,14 +325,18 @@ func getTuple(in []any, options CreateSchemaOptions) (map[string]any, error) {
 		return nil, fmt.Errorf("tuple's second argument must be an array, %v", in)
 	}
 
-	for _, val := range typeSlice {
-		newNode, err := getNodeFromType("", val, false, options)
+	for i, val := range typeSlice {
+		newNode, err := getNestedNode(val, defaults.Child(strconv.Itoa(i)), options)
 		if err != nil {
 			return nil, fmt.Errorf("tuple: %w", err)
 		}
 		items = append(items, newNode)
 	}
-	node["items"] = items
+	if options.Draft == OpenAPI30 && !options.SuppressLogging {
+		fmt.Printf("Warning: OpenAPI 3.0 doesn't support tuples, %v is approximated as a list of any of its "+
+			"element types\n", in[1])
+	}
+	options.Draft.setTupleItems(node, items)
 	node["minItems"] = float64(len(items))
 	node["maxItems"] = float64(len(items))
 
@@ -18,13 +18,15 @@ type ValidationApplyError struct {
 	ErrorMap map[string]error
 }
 
-func parseConditionToNode(ex hcl.Expression, _ string, name string, m *map[string]any) error {
+// parseConditionToNode applies the first translation rule which matches the condition to the node, and returns
+// the JSON Schema keywords which it set.
+func parseConditionToNode(ex hcl.Expression, _ string, name string, m *map[string]any) ([]string, error) {
 	if m == nil {
-		return fmt.Errorf("node is nil")
+		return nil, fmt.Errorf("node is nil")
 	}
 	t, ok := (*m)["type"].(string)
 	if !ok {
-		return fmt.Errorf("cannot apply validation, type is not defined for %v", *m)
+		return nil, fmt.Errorf("cannot apply validation, type is not defined for %v", *m)
 	}
 	functions := map[string]conditionMutator{
 		"contains([...],var.input_parameter)":          contains,
@@ -38,16 +40,18 @@ func parseConditionToNode(ex hcl.Expression, _ string, name string, m *map[strin
 		updatedNode, err := fn(ex, name, t)
 		if err == nil {
 			// apply updated node to m:
+			keywords := make([]string, 0, len(updatedNode))
 			for k, v := range updatedNode {
 				(*m)[k] = v
+				keywords = append(keywords, k)
 			}
 
-			return nil
+			return keywords, nil
 		}
 		errorMap[fnName] = err
 	}
 
-	return ValidationApplyError{ErrConditionNotApplied, errorMap}
+	return nil, ValidationApplyError{ErrConditionNotApplied, errorMap}
 }
 
 func isOneOf(ex hcl.Expression, name string, _ string) (map[string]any, error) {
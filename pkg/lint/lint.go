@@ -0,0 +1,83 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package lint
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+	"github.com/HewlettPackard/terraschema/pkg/reader"
+)
+
+type AnalyseVariablesOptions struct {
+	// IgnoreVariables are left out of the list of unused variables.
+	IgnoreVariables []string
+	// Dialect decides which files are read. The default is reader.DialectTerraform.
+	Dialect reader.Dialect
+}
+
+// Report lists the problems found with the variables of a module.
+type Report struct {
+	// Unused contains the variables which are declared but never referenced. These are candidates for
+	// --ignore-variable, or for removing from the module.
+	Unused []UnusedVariable `json:"unused"`
+	// Undeclared contains the variables which are referenced but never declared. Terraform won't accept these.
+	Undeclared []UndeclaredVariable `json:"undeclared"`
+}
+
+type UnusedVariable struct {
+	Name   string               `json:"name"`
+	Source model.SourceLocation `json:"source"`
+}
+
+type UndeclaredVariable struct {
+	Name       string                 `json:"name"`
+	References []model.SourceLocation `json:"references"`
+}
+
+// AnalyseVariables compares the variables declared in a module with the references to them (var.<name>) in all the
+// expressions of the module, and reports the variables which are unused or undeclared. Both lists are sorted by
+// the name of the variable.
+func AnalyseVariables(path string, options AnalyseVariablesOptions) (Report, error) {
+	return AnalyseVariablesFS(reader.LocalFS{}, filepath.ToSlash(path), options)
+}
+
+// AnalyseVariablesFS is the same as AnalyseVariables, but reads the module in the directory dir of fsys.
+func AnalyseVariablesFS(fsys fs.FS, dir string, options AnalyseVariablesOptions) (Report, error) {
+	report := Report{Unused: []UnusedVariable{}, Undeclared: []UndeclaredVariable{}}
+
+	varMap, err := reader.GetVarMapFS(fsys, dir, reader.GetVarMapOptions{Dialect: options.Dialect})
+	// a module without any variables can still refer to them.
+	if err != nil && !errors.Is(err, reader.ErrNoVariablesFound) {
+		return report, fmt.Errorf("error reading variables at %q: %w", dir, err)
+	}
+
+	references, err := reader.GetVariableReferencesFS(fsys, dir, options.Dialect)
+	if err != nil {
+		return report, fmt.Errorf("error reading references at %q: %w", dir, err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(varMap)) {
+		if _, ok := references[name]; !ok && !slices.Contains(options.IgnoreVariables, name) {
+			report.Unused = append(report.Unused, UnusedVariable{Name: name, Source: varMap[name].Location})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(references)) {
+		if _, ok := varMap[name]; !ok {
+			locations := references[name]
+			slices.SortStableFunc(locations, compareLocations)
+			report.Undeclared = append(report.Undeclared, UndeclaredVariable{Name: name, References: locations})
+		}
+	}
+
+	return report, nil
+}
+
+func compareLocations(a, b model.SourceLocation) int {
+	return cmp.Or(cmp.Compare(a.Filename, b.Filename), cmp.Compare(a.StartLine, b.StartLine))
+}
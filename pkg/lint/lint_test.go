@@ -0,0 +1,59 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package lint
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyseVariables(t *testing.T) {
+	t.Parallel()
+	expected, err := os.ReadFile("../../test/expected/unused-variables/lint.json")
+	require.NoError(t, err)
+
+	// variables.tf.json declares its variables with the array form of "variable", and one of them is only referred to
+	// by its own validation rule, so it is unused.
+	result, err := AnalyseVariables("../../test/modules/unused-variables", AnalyseVariablesOptions{
+		IgnoreVariables: []string{"legacy"},
+	})
+	require.NoError(t, err)
+
+	// marshal and unmarshal to compare with the expected JSON.
+	buf, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var gotMap map[string]any
+	err = json.Unmarshal(buf, &gotMap)
+	require.NoError(t, err)
+
+	var expectedMap map[string]any
+	err = json.Unmarshal(expected, &expectedMap)
+	require.NoError(t, err)
+
+	if d := cmp.Diff(expectedMap, gotMap); d != "" {
+		t.Errorf("Report has incorrect value (-want,+got):\n%s", d)
+	}
+}
+
+func TestAnalyseVariablesFS(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"main.tf": {Data: []byte("locals {\n  name = var.name\n}\n")},
+	}
+
+	// a module without any variable blocks still reports the variables it refers to.
+	result, err := AnalyseVariablesFS(fsys, ".", AnalyseVariablesOptions{})
+	require.NoError(t, err)
+	require.Empty(t, result.Unused)
+	require.Len(t, result.Undeclared, 1)
+	require.Equal(t, "name", result.Undeclared[0].Name)
+	require.Equal(t, 2, result.Undeclared[0].References[0].StartLine)
+
+	_, err = AnalyseVariablesFS(fstest.MapFS{}, ".", AnalyseVariablesOptions{})
+	require.Error(t, err)
+}
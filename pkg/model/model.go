@@ -11,19 +11,25 @@ type VariableBlock struct {
 	Default     hcl.Expression `hcl:"default,optional"`
 	Description *string        `hcl:"description,optional"`
 	Nullable    *bool          `hcl:"nullable,optional"`
-	// Sensitive is ignored.
+	// Sensitive variables are marked as writeOnly in the JSON schema, and their default values are redacted.
 	Sensitive *bool `hcl:"sensitive,optional"`
+	// Ephemeral variables are only available while Terraform is running, and aren't stored in the plan or state.
+	Ephemeral *bool `hcl:"ephemeral,optional"`
 	// Validations blocks can be used to add extra rules to the JSON schema, as long as their conditions
 	// are written in a certain format.
 	Validations []ValidationBlock `hcl:"validation,block"`
 	Type        hcl.Expression    `hcl:"type,optional"`
+	// Deprecated is a message explaining that the variable shouldn't be used any more. It is only supported by
+	// OpenTofu, and is ignored when reading Terraform modules.
+	Deprecated *string `hcl:"deprecated,optional"`
 
 	// ignore other attributes (triggers partial decoding)
 	Other hcl.Body `hcl:",remain"`
 }
 
 type ValidationBlock struct {
-	Condition hcl.Expression `hcl:"condition,attr"`
+	Condition    hcl.Expression `hcl:"condition,attr"`
+	ErrorMessage hcl.Expression `hcl:"error_message,optional"`
 
 	// ignore other attributes (triggers partial decoding)
 	Other hcl.Body `hcl:",remain"`
@@ -35,6 +41,10 @@ type ValidationBlock struct {
 type TranslatedVariable struct {
 	// if the variable has validation blocks, this stores their conditions as a string. This is useful for debugging.
 	ConditionsAsString []string
+	// if the variable has validation blocks, this stores their error messages as a string, in the same order as
+	// ConditionsAsString. An error message which can't be evaluated without context, such as a template referring
+	// to the variable, is stored as its source text instead. Missing error messages are stored as empty strings.
+	ErrorMessagesAsString []string
 	// DefaultAsString is the default value of the variable, as a string. This is useful for debugging complex default values.
 	DefaultAsString *string
 	// TypeAsString is the type of the variable, as a string. This is useful for debugging complex types, such as objects.
@@ -42,6 +52,59 @@ type TranslatedVariable struct {
 	TypeAsString *string
 	// Required is true if and only if the variable has no default value.
 	Required bool
+	// Location is where the variable block is declared in the module.
+	Location SourceLocation
+	// Comments contains the lines of the comment directly above the variable block, without the comment markers.
+	// Only comments starting with # or // are used, and the comment ends at the first blank line.
+	Comments []string
+	// TypeComments contains the comments directly above each attribute of an object in the type of the variable.
+	TypeComments *TypeComments
+	// Order is the position of the variable in the module, counting from 0. Files are read in lexical order, and
+	// variables within a file are counted in the order they are declared. Override files don't change the order.
+	Order int
 	// The variable block used to generate the other fields in this struct.
 	Variable VariableBlock
 }
+
+// SourceLocation is the file and range of lines where a block is declared. Filename is relative to the module
+// directory.
+type SourceLocation struct {
+	Filename  string `json:"file"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// IsSensitive returns true if the variable block has 'sensitive = true' set.
+func (v VariableBlock) IsSensitive() bool {
+	return v.Sensitive != nil && *v.Sensitive
+}
+
+// IsEphemeral returns true if the variable block has 'ephemeral = true' set.
+func (v VariableBlock) IsEphemeral() bool {
+	return v.Ephemeral != nil && *v.Ephemeral
+}
+
+// TypeComments contains the comments on the attributes of an object type, such as:
+//
+//	type = object({
+//	  # The name of the user.
+//	  name = string
+//	})
+//
+// The tree has the same shape as the type: the children of an object are keyed by attribute name, the element of a
+// list, set or map has the key "", and the elements of a tuple are keyed by their index.
+type TypeComments struct {
+	Comments []string
+	// Description is the text of Comments, if descriptions are read from comments.
+	Description *string
+	Children    map[string]*TypeComments
+}
+
+// Child returns the comments of a nested type. It is safe to call on a nil value.
+func (c *TypeComments) Child(key string) *TypeComments {
+	if c == nil {
+		return nil
+	}
+
+	return c.Children[key]
+}
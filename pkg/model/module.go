@@ -0,0 +1,31 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package model
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// ModuleBlock represents a Terraform module block. Only the fields needed to locate the child module are decoded.
+// The module name is stored separately.
+type ModuleBlock struct {
+	// Source is required by Terraform, but it is optional here so that module blocks in override files can be decoded.
+	Source string `hcl:"source,optional"`
+
+	// ignore other attributes (triggers partial decoding)
+	Other hcl.Body `hcl:",remain"`
+}
+
+// ModuleManifest is the manifest written to .terraform/modules/modules.json by 'terraform init', which records where
+// each module called by the configuration has been installed.
+type ModuleManifest struct {
+	Modules []ModuleManifestRecord `json:"Modules"`
+}
+
+// ModuleManifestRecord is a single module in the manifest. Key is the path of module names from the root module to
+// the module, joined by ".", e.g. "net.subnet". Dir is relative to the root module.
+type ModuleManifestRecord struct {
+	Key     string `json:"Key"`
+	Source  string `json:"Source"`
+	Version string `json:"Version,omitempty"`
+	Dir     string `json:"Dir"`
+}
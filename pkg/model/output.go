@@ -0,0 +1,36 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package model
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// OutputBlock represents a Terraform output block. The output name is stored separately.
+type OutputBlock struct {
+	// Value is required by Terraform, but it is optional here so that output blocks in override files can be decoded.
+	Value       hcl.Expression `hcl:"value,optional"`
+	Description *string        `hcl:"description,optional"`
+	Sensitive   *bool          `hcl:"sensitive,optional"`
+
+	// ignore other attributes and blocks, such as depends_on and precondition (triggers partial decoding)
+	Other hcl.Body `hcl:",remain"`
+}
+
+// TranslatedOutput contains the Output struct, as well as some extra information about its value which is used to
+// work out the type of the output.
+type TranslatedOutput struct {
+	// ValueAsString is the value expression of the output, as a string. This is useful for debugging.
+	ValueAsString string
+	// VariableReference is the name of the variable the output refers to, if its value is a direct reference to an
+	// input variable such as var.name. Otherwise, it is empty.
+	VariableReference string
+	// Location is where the output block is declared in the module.
+	Location SourceLocation
+	// The output block used to generate the other fields in this struct.
+	Output OutputBlock
+}
+
+// IsSensitive returns true if the output block has 'sensitive = true' set.
+func (o OutputBlock) IsSensitive() bool {
+	return o.Sensitive != nil && *o.Sensitive
+}
@@ -0,0 +1,21 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package model
+
+// TerraformSettings contains the settings from the terraform blocks of a module which describe what is needed to use
+// it. A module may have more than one terraform block, in which case the settings from all of them are combined.
+type TerraformSettings struct {
+	// RequiredVersion contains the version constraints for Terraform from each terraform block, in the order they
+	// are declared. All of them must be met.
+	RequiredVersion []string
+	// RequiredProviders maps the local name of each provider to its source and version constraint.
+	RequiredProviders map[string]RequiredProvider
+	// Backend is the type of the backend block, e.g. "s3", or "cloud" if a cloud block is used instead.
+	Backend string
+}
+
+// RequiredProvider is a single entry in a required_providers block. Source is empty for providers declared with the
+// legacy syntax, which only contains a version constraint.
+type RequiredProvider struct {
+	Source  string
+	Version string
+}
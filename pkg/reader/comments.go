@@ -0,0 +1,185 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+)
+
+// AnnotationPrefix starts a comment line which sets keywords in the schema, rather than describing the variable, e.g.
+//
+//	# @schema format=email title="Admin email"
+const AnnotationPrefix = "@schema"
+
+// IsAnnotation returns true if a line of a comment is an annotation, and returns the rest of the line.
+func IsAnnotation(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), AnnotationPrefix)
+	if !ok || (rest != "" && !unicode.IsSpace(rune(rest[0]))) {
+		return "", false
+	}
+
+	return rest, true
+}
+
+// commentReader finds the comments above blocks and attributes in a file using the native syntax. The JSON syntax
+// doesn't support comments.
+type commentReader struct {
+	tokens hclsyntax.Tokens
+}
+
+func newCommentReader(file *hcl.File) *commentReader {
+	// the file has already been parsed successfully, so the tokens don't need to be checked for errors again.
+	tokens, _ := hclsyntax.LexConfig(file.Bytes, "", hcl.InitialPos)
+
+	return &commentReader{tokens: tokens}
+}
+
+// leadingComments returns the lines of the comment directly above the token starting at pos, without the comment
+// markers. Comments which follow something else on the same line, or which are separated by a blank line, are not
+// included.
+func (c *commentReader) leadingComments(pos hcl.Pos) []string {
+	if c == nil {
+		return nil
+	}
+	i := sort.Search(len(c.tokens), func(i int) bool { return c.tokens[i].Range.Start.Byte >= pos.Byte })
+
+	// line comments include the newline at the end of the line, so there is no newline token between them.
+	start := i
+	for start > 0 && isLineComment(c.tokens[start-1]) {
+		start--
+	}
+	// the comment must be on its own line, rather than at the end of the previous line.
+	for start < i && start > 0 && !isLineStart(c.tokens[start-1]) {
+		start++
+	}
+
+	lines := []string{}
+	for _, token := range c.tokens[start:i] {
+		lines = append(lines, commentText(token))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return lines
+}
+
+// typeComments returns the comments directly above each attribute of the object types in a type expression.
+func (c *commentReader) typeComments(expr hcl.Expression) *model.TypeComments {
+	if c == nil {
+		return nil
+	}
+	call, ok := expr.(*hclsyntax.FunctionCallExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil
+	}
+
+	out := &model.TypeComments{Children: make(map[string]*model.TypeComments)}
+	switch call.Name {
+	case "optional":
+		// optional only wraps the type of an attribute, so it doesn't add another level to the tree.
+		return c.typeComments(call.Args[0])
+	case "list", "set", "map":
+		if child := c.typeComments(call.Args[0]); child != nil {
+			out.Children[""] = child
+		}
+	case "tuple":
+		if elements, ok := call.Args[0].(*hclsyntax.TupleConsExpr); ok {
+			for i, element := range elements.Exprs {
+				if child := c.typeComments(element); child != nil {
+					out.Children[strconv.Itoa(i)] = child
+				}
+			}
+		}
+	case "object":
+		if attributes, ok := call.Args[0].(*hclsyntax.ObjectConsExpr); ok {
+			for _, item := range attributes.Items {
+				c.addAttributeComments(out, item)
+			}
+		}
+	}
+	if len(out.Children) == 0 {
+		return nil
+	}
+
+	return out
+}
+
+func (c *commentReader) addAttributeComments(out *model.TypeComments, item hclsyntax.ObjectConsItem) {
+	name, d := stringValue(item.KeyExpr)
+	if d.HasErrors() {
+		return
+	}
+	child := c.typeComments(item.ValueExpr)
+	comments := c.leadingComments(item.KeyExpr.Range().Start)
+	if comments != nil {
+		if child == nil {
+			child = &model.TypeComments{}
+		}
+		child.Comments = comments
+	}
+	if child != nil {
+		out.Children[name] = child
+	}
+}
+
+func isLineComment(token hclsyntax.Token) bool {
+	return token.Type == hclsyntax.TokenComment && strings.HasSuffix(string(token.Bytes), "\n")
+}
+
+// isLineStart returns true if the token after this one is at the start of a line.
+func isLineStart(token hclsyntax.Token) bool {
+	return token.Type == hclsyntax.TokenNewline || isLineComment(token)
+}
+
+func commentText(token hclsyntax.Token) string {
+	text := strings.TrimRight(string(token.Bytes), "\r\n")
+	text = strings.TrimPrefix(text, "#")
+	text = strings.TrimPrefix(text, "//")
+
+	return strings.TrimPrefix(text, " ")
+}
+
+// setCommentDescriptions uses the comments of a variable as its description, and as the descriptions of the
+// attributes in its type, where the variable doesn't already have a description.
+func setCommentDescriptions(v model.TranslatedVariable) model.TranslatedVariable {
+	if v.Variable.Description == nil {
+		v.Variable.Description = commentDescription(v.Comments)
+	}
+	setTypeCommentDescriptions(v.TypeComments)
+
+	return v
+}
+
+func setTypeCommentDescriptions(c *model.TypeComments) {
+	if c == nil {
+		return
+	}
+	c.Description = commentDescription(c.Comments)
+	for _, child := range c.Children {
+		setTypeCommentDescriptions(child)
+	}
+}
+
+// commentDescription returns the text of a comment without any annotations, or nil if there is no other text.
+func commentDescription(lines []string) *string {
+	text := []string{}
+	for _, line := range lines {
+		if _, ok := IsAnnotation(line); !ok {
+			text = append(text, strings.TrimRight(line, " \t"))
+		}
+	}
+	description := strings.TrimSpace(strings.Join(text, "\n"))
+	if description == "" {
+		return nil
+	}
+
+	return &description
+}
@@ -0,0 +1,65 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// DiagnosticsError is returned when a module can't be read because of problems which can be traced back to a
+// location in its source files. Files contains every file which was parsed, so that the diagnostics can be printed
+// along with a snippet of the source, for example with hcl.NewDiagnosticTextWriter.
+type DiagnosticsError struct {
+	Diagnostics hcl.Diagnostics
+	Files       map[string]*hcl.File
+}
+
+var _ error = &DiagnosticsError{}
+
+func newDiagnosticsError(d hcl.Diagnostics, parser *hclparse.Parser) *DiagnosticsError {
+	return &DiagnosticsError{Diagnostics: d, Files: parser.Files()}
+}
+
+func (e *DiagnosticsError) Error() string {
+	return e.Diagnostics.Error()
+}
+
+// Unwrap allows errors.As to be used to retrieve the hcl.Diagnostics directly.
+func (e *DiagnosticsError) Unwrap() error {
+	return e.Diagnostics
+}
+
+// duplicateDiagnostics returns the error Terraform reports when a block with the same type and name is declared more
+// than once in a module. The first diagnostic points at the second declaration, and is followed by one which points
+// at the first declaration, so that a snippet of both is shown. A single diagnostic can't do this, since its context
+// must be in the same file as its subject.
+func duplicateDiagnostics(blockType string, name string, existing hcl.Range, block *hcl.Block) hcl.Diagnostics {
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Duplicate %s declaration", blockType),
+			Detail: fmt.Sprintf(
+				"A %s named %q was already declared at %s. %s names must be unique within a module.",
+				blockType, name, existing, capitalise(blockType),
+			),
+			Subject: &block.DefRange,
+		},
+		{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Previous %s declaration", blockType),
+			Detail:   fmt.Sprintf("The %s %q is declared again at %s.", blockType, name, block.DefRange),
+			Subject:  existing.Ptr(),
+		},
+	}
+}
+
+func capitalise(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,76 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Dialect is the tool which the module is written for. It decides which files are read, and which arguments are
+// allowed in variable blocks.
+type Dialect string
+
+const (
+	// DialectTerraform reads .tf and .tf.json files. This is the default if no dialect is set.
+	DialectTerraform Dialect = "terraform"
+	// DialectOpenTofu also reads .tofu and .tofu.json files, which replace the .tf and .tf.json files with the same
+	// name, in the same way as OpenTofu 1.8 and later. It also allows OpenTofu only arguments, such as 'deprecated'.
+	DialectOpenTofu Dialect = "tofu"
+)
+
+var ErrUnknownDialect = fmt.Errorf("unknown dialect, must be one of %q or %q", DialectTerraform, DialectOpenTofu)
+
+// ParseDialect returns the dialect with the given name. An empty name is the same as DialectTerraform.
+func ParseDialect(name string) (Dialect, error) {
+	switch Dialect(name) {
+	case "", DialectTerraform:
+		return DialectTerraform, nil
+	case DialectOpenTofu:
+		return DialectOpenTofu, nil
+	default:
+		return "", fmt.Errorf("%q: %w", name, ErrUnknownDialect)
+	}
+}
+
+// extensions returns the file extensions of configuration files, with the extensions which take precedence first.
+func (d Dialect) extensions() []string {
+	if d == DialectOpenTofu {
+		return []string{".tofu", ".tofu.json", ".tf", ".tf.json"}
+	}
+
+	return []string{".tf", ".tf.json"}
+}
+
+// selectFiles returns the names of the configuration files in a directory which are used by the dialect. In OpenTofu,
+// a .tofu file replaces the .tf file with the same name, and a .tofu.json file replaces the .tf.json file.
+func (d Dialect) selectFiles(names []string) []string {
+	selected := []string{}
+	for _, name := range names {
+		ext, ok := configExtension(name, d.extensions())
+		if !ok {
+			continue
+		}
+		if d == DialectOpenTofu && strings.HasPrefix(ext, ".tf") {
+			replacement := strings.TrimSuffix(name, ext) + strings.Replace(ext, ".tf", ".tofu", 1)
+			if slices.Contains(names, replacement) {
+				continue
+			}
+		}
+		selected = append(selected, name)
+	}
+
+	return selected
+}
+
+// configExtension returns the extension of a configuration file. The extensions are checked in order, so that
+// ".tofu.json" is returned rather than ".json" for example.
+func configExtension(name string, extensions []string) (string, bool) {
+	for _, ext := range extensions {
+		if strings.HasSuffix(name, ext) {
+			return ext, true
+		}
+	}
+
+	return "", false
+}
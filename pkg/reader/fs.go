@@ -0,0 +1,20 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalFS is an fs.FS which opens files by their path on the local file system. Unlike os.DirFS, it isn't rooted at
+// a directory: names may be absolute or relative to the working directory, and may contain "..". This is used by the
+// path based functions in this module, so that diagnostics refer to files by the path given by the user, and so that
+// modules can call child modules outside of the input directory.
+type LocalFS struct{}
+
+var _ fs.FS = LocalFS{}
+
+func (LocalFS) Open(name string) (fs.File, error) {
+	return os.Open(filepath.FromSlash(name))
+}
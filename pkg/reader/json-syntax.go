@@ -0,0 +1,51 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// jsonStringValue returns the literal value of an expression from a JSON syntax file if it is a string,
+// and fallback otherwise.
+func jsonStringValue(in hcl.Expression, fallback string) string {
+	// evaluating with a nil context returns strings verbatim, without parsing them as templates.
+	v, d := in.Value(nil)
+	if d.HasErrors() || v.IsNull() || !v.IsKnown() || v.Type() != cty.String {
+		return fallback
+	}
+
+	return v.AsString()
+}
+
+// jsonTemplateToNative converts an expression from a JSON syntax file, such as "${var.age >= 0}", into the
+// equivalent native syntax expression, so that it can be inspected in the same way as an expression from a .tf
+// file. It also returns the source text of the converted expression. If the expression isn't a string, the
+// returned expression is nil.
+func jsonTemplateToNative(in hcl.Expression) (hcl.Expression, string, hcl.Diagnostics) {
+	v, d := in.Value(nil)
+	if d.HasErrors() || v.IsNull() || !v.IsKnown() || v.Type() != cty.String {
+		return nil, "", nil
+	}
+	src := []byte(v.AsString())
+
+	// The byte offsets are relative to the string value rather than the file, since escape sequences in the
+	// JSON string mean they can't be mapped back exactly. The line and column are kept for diagnostics.
+	start := in.Range().Start
+	ex, d := hclsyntax.ParseTemplate(src, in.Range().Filename, hcl.Pos{
+		Line:   start.Line,
+		Column: start.Column + 1, // skip over the opening quote mark
+		Byte:   0,
+	})
+	if d.HasErrors() {
+		return nil, "", d
+	}
+
+	// a template which consists of a single interpolation evaluates to the wrapped expression directly.
+	if wrapped, ok := ex.(*hclsyntax.TemplateWrapExpr); ok {
+		ex = wrapped.Wrapped
+	}
+
+	return ex, string(ex.Range().SliceBytes(src)), nil
+}
@@ -0,0 +1,69 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+)
+
+// ManifestPath is where 'terraform init' records the directory of each module it installs, relative to the root
+// module.
+const ManifestPath = ".terraform/modules/modules.json"
+
+var ErrManifestNotFound = fmt.Errorf("module manifest %q not found, run 'terraform init' first", ManifestPath)
+
+// GetModuleManifest reads the module manifest written by 'terraform init' in the root module at path. Only the
+// records for module calls are returned, the record for the root module itself is skipped.
+func GetModuleManifest(path string) ([]model.ModuleManifestRecord, error) {
+	return GetModuleManifestFS(LocalFS{}, filepath.ToSlash(path))
+}
+
+// GetModuleManifestFS is the same as GetModuleManifest, but reads the root module in the directory dir of fsys.
+func GetModuleManifestFS(fsys fs.FS, dir string) ([]model.ModuleManifestRecord, error) {
+	manifestPath := path.Join(dir, ManifestPath)
+	data, err := fs.ReadFile(fsys, manifestPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrManifestNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read %q: %w", manifestPath, err)
+	}
+
+	manifest := model.ModuleManifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("could not decode %q: %w", manifestPath, err)
+	}
+
+	records := []model.ModuleManifestRecord{}
+	for _, record := range manifest.Modules {
+		if record.Key == "" {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// ResolveManifestDir returns the directory of a module installed by 'terraform init', given the directory of the
+// root module. It returns false if the directory is outside of fsys, which can't happen for LocalFS.
+func ResolveManifestDir(fsys fs.FS, dir string, record model.ModuleManifestRecord) (string, bool) {
+	recordDir := filepath.ToSlash(record.Dir)
+	if _, ok := fsys.(LocalFS); ok && filepath.IsAbs(record.Dir) {
+		return recordDir, true
+	}
+
+	return ResolveSource(fsys, dir, recordDir)
+}
+
+// ModuleAddress returns the address of a module from its key in the module manifest, e.g. the key "net.subnet" has
+// the address "module.net.module.subnet".
+func ModuleAddress(key string) string {
+	return "module." + strings.ReplaceAll(key, ".", ".module.")
+}
@@ -0,0 +1,103 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+)
+
+var moduleFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       "module",
+			LabelNames: []string{"name"},
+		},
+	},
+}
+
+// GetModuleCalls reads all .tf and .tf.json files in a directory and returns a map of module names to the module blocks which
+// call them. Only the module source is decoded, since that is all that is needed to find the child module.
+func GetModuleCalls(path string) (map[string]model.ModuleBlock, error) {
+	return GetModuleCallsFS(LocalFS{}, filepath.ToSlash(path), DialectTerraform)
+}
+
+// GetModuleCallsFS is the same as GetModuleCalls, but reads the module in the directory dir of fsys, using the files
+// selected by dialect.
+func GetModuleCallsFS(fsys fs.FS, dir string, dialect Dialect) (map[string]model.ModuleBlock, error) {
+	files, err := getFiles(fsys, dir, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+
+	moduleMap := make(map[string]model.ModuleBlock)
+	declarations := make(map[string]hcl.Range)
+	var diags hcl.Diagnostics
+	for _, fileName := range files {
+		file, d := parseFile(parser, fsys, fileName)
+		if d.HasErrors() {
+			return nil, newDiagnosticsError(d, parser)
+		}
+
+		blocks, _, d := file.Body.PartialContent(moduleFileSchema)
+		if d.HasErrors() {
+			return nil, newDiagnosticsError(d, parser)
+		}
+		for _, block := range blocks.Blocks {
+			name := block.Labels[0]
+			module := model.ModuleBlock{}
+			d := gohcl.DecodeBody(block.Body, nil, &module)
+			if d.HasErrors() {
+				return nil, newDiagnosticsError(d, parser)
+			}
+			if isOverrideFile(fileName) {
+				base, ok := moduleMap[name]
+				if !ok {
+					return nil, newDiagnosticsError(missingBaseDiagnostic("module", name, block), parser)
+				}
+				if module.Source == "" {
+					module.Source = base.Source
+				}
+			} else if existing, ok := declarations[name]; ok {
+				diags = append(diags, duplicateDiagnostics("module", name, existing, block)...)
+
+				continue
+			} else {
+				declarations[name] = block.DefRange
+			}
+			moduleMap[name] = module
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, newDiagnosticsError(diags, parser)
+	}
+
+	return moduleMap, nil
+}
+
+// IsLocalSource returns true if a module source refers to a directory on the local filesystem. Terraform only treats
+// a source as a local path if it starts with "./" or "../", everything else is downloaded by 'terraform init'.
+func IsLocalSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// ResolveSource returns the directory of a module which is called with a local source from the module in the
+// directory dir of fsys. It returns false if the directory is outside of fsys, which can't happen for LocalFS.
+func ResolveSource(fsys fs.FS, dir string, source string) (string, bool) {
+	childDir := path.Join(dir, source)
+	if _, ok := fsys.(LocalFS); ok {
+		return childDir, true
+	}
+
+	return childDir, fs.ValidPath(childDir)
+}
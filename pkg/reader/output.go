@@ -0,0 +1,192 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+)
+
+var outputFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       "output",
+			LabelNames: []string{"name"},
+		},
+	},
+}
+
+var ErrNoOutputsFound = fmt.Errorf("tf files don't contain any outputs")
+
+// GetOutputMap reads all .tf and .tf.json files in a directory and returns a map of output names to their translated
+// values. Override files are merged in the same way as for variables.
+func GetOutputMap(path string) (map[string]model.TranslatedOutput, error) {
+	return GetOutputMapFS(LocalFS{}, filepath.ToSlash(path), DialectTerraform)
+}
+
+// GetOutputMapFS is the same as GetOutputMap, but reads the module in the directory dir of fsys, using the files
+// selected by dialect.
+func GetOutputMapFS(fsys fs.FS, dir string, dialect Dialect) (map[string]model.TranslatedOutput, error) {
+	files, err := getFiles(fsys, dir, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+
+	outputMap := make(map[string]model.TranslatedOutput)
+	declarations := make(map[string]hcl.Range)
+	var diags hcl.Diagnostics
+	for _, fileName := range files {
+		file, d := parseFile(parser, fsys, fileName)
+		if d.HasErrors() {
+			return nil, newDiagnosticsError(d, parser)
+		}
+
+		blocks, _, d := file.Body.PartialContent(outputFileSchema)
+		if d.HasErrors() {
+			return nil, newDiagnosticsError(d, parser)
+		}
+		for _, block := range blocks.Blocks {
+			name, translated, d := getTranslatedOutputFromBlock(block, file)
+			if d.HasErrors() {
+				return nil, newDiagnosticsError(d, parser)
+			}
+			if isOverrideFile(fileName) {
+				base, ok := outputMap[name]
+				if !ok {
+					return nil, newDiagnosticsError(missingBaseDiagnostic("output", name, block), parser)
+				}
+				translated = mergeOutputOverride(base, translated)
+			} else if existing, ok := declarations[name]; ok {
+				diags = append(diags, duplicateDiagnostics("output", name, existing, block)...)
+
+				continue
+			} else if translated.Output.Value == nil {
+				return nil, newDiagnosticsError(missingValueDiagnostic(block), parser)
+			} else {
+				declarations[name] = block.DefRange
+			}
+			outputMap[name] = translated
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, newDiagnosticsError(diags, parser)
+	}
+
+	if len(outputMap) == 0 {
+		return nil, ErrNoOutputsFound
+	}
+
+	return outputMap, nil
+}
+
+func getTranslatedOutputFromBlock(block *hcl.Block, file *hcl.File) (string, model.TranslatedOutput, hcl.Diagnostics) {
+	name := block.Labels[0]
+	output := model.OutputBlock{}
+	d := gohcl.DecodeBody(block.Body, nil, &output)
+	if d.HasErrors() {
+		return name, model.TranslatedOutput{}, d
+	}
+	output.Value = filterMissingExpression(output.Value, block.Body.MissingItemRange())
+
+	out := model.TranslatedOutput{Output: output, Location: getSourceLocation(block)}
+	if output.Value == nil {
+		return name, out, nil
+	}
+
+	out.ValueAsString = printToString(output.Value, file)
+	value := output.Value
+	if hcljson.IsJSONExpression(value) {
+		native, valueAsString, d := jsonTemplateToNative(value)
+		if d.HasErrors() {
+			return name, model.TranslatedOutput{}, d
+		}
+		if native != nil {
+			value = native
+			out.ValueAsString = valueAsString
+		}
+	}
+	out.VariableReference = variableReference(value)
+
+	return name, out, nil
+}
+
+// variableReference returns the name of the variable an expression refers to, if the expression is a direct reference
+// to an input variable such as var.name. Otherwise, it returns an empty string.
+func variableReference(expr hcl.Expression) string {
+	// JSON syntax expressions must be converted to native syntax first, since a plain JSON string would be parsed as
+	// a traversal, rather than a literal string.
+	if hcljson.IsJSONExpression(expr) {
+		return ""
+	}
+	traversal, d := hcl.AbsTraversalForExpr(expr)
+	if d.HasErrors() || len(traversal) != 2 || traversal.RootName() != "var" {
+		return ""
+	}
+	attr, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return ""
+	}
+
+	return attr.Name
+}
+
+// GetLiteralValueType returns the type of an expression as a JSON object in the same format as GetTypeConstraint, if
+// the expression is a literal value which doesn't refer to anything else. Otherwise, it returns false.
+func GetLiteralValueType(expr hcl.Expression) (any, bool) {
+	if expr == nil || len(expr.Variables()) != 0 {
+		return nil, false
+	}
+	// JSON syntax templates are only evaluated with a context, and function calls fail without any functions.
+	v, d := expr.Value(&hcl.EvalContext{})
+	if d.HasErrors() || !v.IsWhollyKnown() || v.IsNull() || v.Type() == cty.DynamicPseudoType {
+		return nil, false
+	}
+	t, err := typeToJSONObject(v.Type())
+	if err != nil {
+		return nil, false
+	}
+
+	return t, true
+}
+
+// mergeOutputOverride applies an output block from an override file to the output it overrides. Each argument which
+// is set in the override replaces the original, and the rest are left as they are.
+func mergeOutputOverride(base, override model.TranslatedOutput) model.TranslatedOutput {
+	out := base
+	if override.Output.Value != nil {
+		out.Output.Value = override.Output.Value
+		out.ValueAsString = override.ValueAsString
+		out.VariableReference = override.VariableReference
+	}
+	if override.Output.Description != nil {
+		out.Output.Description = override.Output.Description
+	}
+	if override.Output.Sensitive != nil {
+		out.Output.Sensitive = override.Output.Sensitive
+	}
+
+	return out
+}
+
+// missingValueDiagnostic returns the error Terraform reports when an output block doesn't have a value.
+func missingValueDiagnostic(block *hcl.Block) hcl.Diagnostics {
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Missing required argument",
+			Detail:   "The argument \"value\" is required, but no definition was found.",
+			Subject:  block.Body.MissingItemRange().Ptr(),
+		},
+	}
+}
@@ -0,0 +1,95 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+)
+
+// isOverrideFile returns true if the file is a Terraform override file, i.e. it is called override.tf,
+// override.tf.json, or its name ends with _override.tf or _override.tf.json. The same applies to OpenTofu's .tofu
+// and .tofu.json files.
+// See https://developer.hashicorp.com/terraform/language/files/override
+func isOverrideFile(fileName string) bool {
+	baseName := filepath.Base(fileName)
+	baseName = strings.TrimSuffix(baseName, ".json")
+	baseName = strings.TrimSuffix(baseName, ".tf")
+	baseName = strings.TrimSuffix(baseName, ".tofu")
+
+	return baseName == "override" || strings.HasSuffix(baseName, "_override")
+}
+
+// mergeVariableOverride applies a variable block from an override file to the variable it overrides. Each argument
+// which is set in the override replaces the original, and the rest are left as they are. If the override contains
+// any validation blocks, they replace all the original validation blocks.
+func mergeVariableOverride(base, override model.TranslatedVariable) model.TranslatedVariable {
+	out := base
+	if override.Variable.Default != nil {
+		out.Variable.Default = override.Variable.Default
+		out.DefaultAsString = override.DefaultAsString
+		out.Required = override.Required
+	}
+	if override.Variable.Type != nil {
+		out.Variable.Type = override.Variable.Type
+		out.TypeAsString = override.TypeAsString
+		out.TypeComments = override.TypeComments
+	}
+	if override.Comments != nil {
+		out.Comments = override.Comments
+	}
+	if override.Variable.Description != nil {
+		out.Variable.Description = override.Variable.Description
+	}
+	if override.Variable.Nullable != nil {
+		out.Variable.Nullable = override.Variable.Nullable
+	}
+	if override.Variable.Sensitive != nil {
+		out.Variable.Sensitive = override.Variable.Sensitive
+	}
+	if override.Variable.Ephemeral != nil {
+		out.Variable.Ephemeral = override.Variable.Ephemeral
+	}
+	if override.Variable.Deprecated != nil {
+		out.Variable.Deprecated = override.Variable.Deprecated
+	}
+	if len(override.Variable.Validations) != 0 {
+		out.Variable.Validations = override.Variable.Validations
+		out.ConditionsAsString = override.ConditionsAsString
+		out.ErrorMessagesAsString = override.ErrorMessagesAsString
+	}
+
+	return out
+}
+
+// missingBaseDiagnostic returns the error Terraform reports when an override file contains a block which doesn't
+// exist in any of the primary configuration files.
+func missingBaseDiagnostic(blockType string, name string, block *hcl.Block) hcl.Diagnostics {
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Missing base %s declaration to override", blockType),
+			Detail: fmt.Sprintf(
+				"There is no %s named %q. An override file can only override a %s that was already declared "+
+					"in a primary configuration file.",
+				blockType, name, blockType,
+			),
+			Subject: &block.DefRange,
+		},
+	}
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
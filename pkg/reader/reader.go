@@ -2,12 +2,21 @@
 package reader
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/HewlettPackard/terraschema/pkg/model"
 )
@@ -22,78 +31,239 @@ var fileSchema = &hcl.BodySchema{
 }
 
 var (
-	ErrFilesNotFound    = fmt.Errorf("no .tf files found")
+	ErrFilesNotFound    = fmt.Errorf("no .tf or .tf.json files found")
 	ErrNoVariablesFound = fmt.Errorf("tf files don't contain any variables")
 )
 
-// GetVarMap reads all .tf files in a directory and returns a map of variable names to their translated values.
+type GetVarMapOptions struct {
+	DebugOut bool
+	// ContinueOnError reads every file in the module, even if some of them can't be parsed or contain variables
+	// which can't be decoded. All the problems found are returned together as a *DiagnosticsError, alongside a map of
+	// the variables which were read successfully.
+	ContinueOnError bool
+	// Dialect decides which files are read, and which arguments are allowed in variable blocks. The default is
+	// DialectTerraform.
+	Dialect Dialect
+	// CommentDescriptions uses the comment above a variable block as its description, if it doesn't have a
+	// description argument. The comments above the attributes of object types are used as the Description of
+	// their TypeComments. Lines containing @schema annotations are left out.
+	CommentDescriptions bool
+}
+
+// GetVarMap reads all .tf and .tf.json files in a directory and returns a map of variable names to their translated values.
 // For the purpose of this application, all that matters is the model.VariableBlock contained in this, which
 // contains a direct unmarshal of the block itself using the hcl package. The rest of the information is for
 // debugging purposes, and to simplify the process of deciding if a variable is 'required' later. Note: in 'strict'
 // mode, all variables are required, regardless of whether they have a default value or not.
 func GetVarMap(path string, debugOut bool) (map[string]model.TranslatedVariable, error) {
-	// read all tf files in directory
-	files, err := filepath.Glob(filepath.Join(path, "*.tf"))
+	return GetVarMapWithOptions(path, GetVarMapOptions{DebugOut: debugOut})
+}
+
+// GetVarMapWithOptions is the same as GetVarMap, but allows more control over how the module is read. Note: if
+// ContinueOnError is set, both the map and the error may be non-nil.
+func GetVarMapWithOptions(path string, options GetVarMapOptions) (map[string]model.TranslatedVariable, error) {
+	return GetVarMapFS(LocalFS{}, filepath.ToSlash(path), options)
+}
+
+// GetVarMapFS is the same as GetVarMapWithOptions, but reads the module in the directory dir of fsys. This allows
+// modules to be read from memory or from an archive, without writing them to disk first. Use "." for the root of fsys.
+func GetVarMapFS(fsys fs.FS, dir string, options GetVarMapOptions) (map[string]model.TranslatedVariable, error) {
+	files, err := getFiles(fsys, dir, options.Dialect)
 	if err != nil {
 		return nil, err
 	}
-	if len(files) == 0 {
-		return nil, ErrFilesNotFound
-	}
 
-	if debugOut {
-		fmt.Printf("Debug: found the following files in %q:\n", path)
+	if options.DebugOut {
+		fmt.Printf("Debug: found the following files in %q:\n", dir)
 	}
 
-	parser := hclparse.NewParser()
-
-	varMap := make(map[string]model.TranslatedVariable)
+	r := &varMapReader{
+		fsys:         fsys,
+		parser:       hclparse.NewParser(),
+		options:      options,
+		varMap:       make(map[string]model.TranslatedVariable),
+		declarations: make(map[string]hcl.Range),
+	}
 	for _, fileName := range files {
-		if debugOut {
+		if options.DebugOut {
 			fmt.Printf("\t%q, with variable(s):\n", fileName)
 		}
 
-		file, d := parser.ParseHCLFile(fileName)
-		if d.HasErrors() {
-			return nil, d
+		d := r.readFile(fileName)
+		if d.HasErrors() && !options.ContinueOnError {
+			return nil, newDiagnosticsError(d, r.parser)
+		}
+		r.diags = append(r.diags, d...)
+	}
+
+	// this is done after all the files are read, so that a description argument in an override file takes precedence
+	// over the comment above the original block. It is also done before the errors are checked, so that the
+	// variables returned with ContinueOnError have their descriptions too.
+	if options.CommentDescriptions {
+		for name, v := range r.varMap {
+			r.varMap[name] = setCommentDescriptions(v)
 		}
+	}
 
-		blocks, _, d := file.Body.PartialContent(fileSchema)
+	// duplicate declarations don't stop the other files from being read, so that all of them are reported together.
+	if r.diags.HasErrors() {
+		if options.ContinueOnError && len(r.varMap) != 0 {
+			return r.varMap, newDiagnosticsError(r.diags, r.parser)
+		}
+
+		return nil, newDiagnosticsError(r.diags, r.parser)
+	}
+
+	if len(r.varMap) == 0 {
+		return nil, ErrNoVariablesFound
+	}
+
+	return r.varMap, nil
+}
+
+// varMapReader holds the state which is built up while reading the files of a module.
+type varMapReader struct {
+	fsys    fs.FS
+	parser  *hclparse.Parser
+	options GetVarMapOptions
+	varMap  map[string]model.TranslatedVariable
+	// declarations keeps track of where each variable was first declared, so that duplicates can be reported.
+	declarations map[string]hcl.Range
+	// diags holds problems which don't stop the rest of the module from being read.
+	diags hcl.Diagnostics
+}
+
+// readFile adds the variables in a file to the var map. Variables which can't be read are skipped, and the
+// diagnostics returned are the errors which would stop the module from being read, unless ContinueOnError is set.
+func (r *varMapReader) readFile(fileName string) hcl.Diagnostics {
+	file, d := parseFile(r.parser, r.fsys, fileName)
+	if d.HasErrors() {
+		// variables in a file with syntax errors may be incomplete, so none of them are used.
+		return d
+	}
+
+	blocks, _, d := file.Body.PartialContent(fileSchema)
+	if d.HasErrors() {
+		return d
+	}
+
+	var comments *commentReader
+	if !strings.HasSuffix(fileName, ".json") {
+		comments = newCommentReader(file)
+	}
+
+	var diags hcl.Diagnostics
+	for _, block := range blocks.Blocks {
+		name, translated, d := getTranslatedVariableFromBlock(block, file, r.options.Dialect)
 		if d.HasErrors() {
-			return nil, d
+			diags = append(diags, d...)
+
+			continue
 		}
-		for _, block := range blocks.Blocks {
-			name, translated, err := getTranslatedVariableFromBlock(block, file)
-			if err != nil {
-				return nil, fmt.Errorf("error getting parsing %q: %w", name, err)
-			}
-			varMap[name] = translated
+		translated.Comments = comments.leadingComments(block.DefRange.Start)
+		translated.TypeComments = comments.typeComments(translated.Variable.Type)
+		if isOverrideFile(fileName) {
+			base, ok := r.varMap[name]
+			if !ok {
+				diags = append(diags, missingBaseDiagnostic("variable", name, block)...)
 
-			if debugOut {
-				fmt.Printf("\t\t%s\n", name)
+				continue
 			}
+			translated = mergeVariableOverride(base, translated)
+		} else if existing, ok := r.declarations[name]; ok {
+			r.diags = append(r.diags, duplicateDiagnostics("variable", name, existing, block)...)
+
+			continue
+		} else {
+			translated.Order = len(r.declarations)
+			r.declarations[name] = block.DefRange
+		}
+		r.varMap[name] = translated
+
+		if r.options.DebugOut {
+			fmt.Printf("\t\t%s\n", name)
 		}
 	}
 
-	if len(varMap) == 0 {
-		return nil, ErrNoVariablesFound
+	return diags
+}
+
+// getFiles returns the paths of all .tf and .tf.json files in the root of a directory, in lexical order. Override
+// files are moved to the end of the list, since Terraform merges them into the configuration after all other files.
+// The OpenTofu dialect also returns .tofu and .tofu.json files, and leaves out the files they replace.
+func getFiles(fsys fs.FS, dir string, dialect Dialect) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("could not read directory %q: %w", dir, err)
 	}
 
-	return varMap, nil
+	names := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	files := []string{}
+	for _, name := range dialect.selectFiles(names) {
+		files = append(files, path.Join(dir, name))
+	}
+	if len(files) == 0 {
+		return nil, ErrFilesNotFound
+	}
+	slices.Sort(files)
+	slices.SortStableFunc(files, func(a, b string) int {
+		return compareBool(isOverrideFile(a), isOverrideFile(b))
+	})
+
+	return files, nil
+}
+
+// parseFile parses a file using the native HCL syntax, or the JSON syntax if the file has a .json extension.
+func parseFile(parser *hclparse.Parser, fsys fs.FS, fileName string) (*hcl.File, hcl.Diagnostics) {
+	src, err := fs.ReadFile(fsys, fileName)
+	if err != nil {
+		// this is the same diagnostic that the parser returns when it reads files from disk itself.
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read file",
+				Detail:   fmt.Sprintf("The configuration file %q could not be read.", fileName),
+			},
+		}
+	}
+
+	if strings.HasSuffix(fileName, ".json") {
+		return parser.ParseJSON(src, fileName)
+	}
+
+	return parser.ParseHCL(src, fileName)
 }
 
-func getTranslatedVariableFromBlock(block *hcl.Block, file *hcl.File) (string, model.TranslatedVariable, error) {
+func getTranslatedVariableFromBlock(
+	block *hcl.Block,
+	file *hcl.File,
+	dialect Dialect,
+) (string, model.TranslatedVariable, hcl.Diagnostics) {
 	name := block.Labels[0]
 	variable := model.VariableBlock{}
 	d := gohcl.DecodeBody(block.Body, nil, &variable)
 	if d.HasErrors() {
 		return name, model.TranslatedVariable{}, d
 	}
+	if dialect != DialectOpenTofu {
+		// 'deprecated' is only supported by OpenTofu, so Terraform modules are read as if it wasn't there.
+		variable.Deprecated = nil
+	}
 
-	variable.Default = filterMissingExpression(variable.Default)
-	variable.Type = filterMissingExpression(variable.Type)
+	missing := block.Body.MissingItemRange()
+	variable.Default = filterMissingExpression(variable.Default, missing)
+	variable.Type = filterMissingExpression(variable.Type, missing)
+	if d := checkTypeAndDefault(variable); d.HasErrors() {
+		return name, model.TranslatedVariable{}, d
+	}
 
-	out := model.TranslatedVariable{Variable: variable, Required: true}
+	out := model.TranslatedVariable{Variable: variable, Required: true, Location: getSourceLocation(block)}
 
 	// Get type, default, and condition as strings and add them to the translated variable struct.
 	// This is to make the code easier to debug, since hcl.Expressions are difficult to read out of context.
@@ -108,28 +278,102 @@ func getTranslatedVariableFromBlock(block *hcl.Block, file *hcl.File) (string, m
 	// check if 'type' exists
 	if variable.Type != nil {
 		typeAsString := printToString(variable.Type, file)
+		if hcljson.IsJSONExpression(variable.Type) {
+			// type expressions are given as a plain string in JSON syntax. The hcl package already knows how to
+			// interpret them, so only the string representation needs to change.
+			typeAsString = jsonStringValue(variable.Type, typeAsString)
+		}
 		out.TypeAsString = &typeAsString
 	}
 
 	// plaintext print all condition expressions into the ConditionsAsString field.
 	out.ConditionsAsString = make([]string, len(variable.Validations))
+	out.ErrorMessagesAsString = make([]string, len(variable.Validations))
 	for i, validation := range variable.Validations {
+		// the remaining body of the validation block has the same missing item range as the block itself.
+		errorMessage := filterMissingExpression(validation.ErrorMessage, validation.Other.MissingItemRange())
+		out.Variable.Validations[i].ErrorMessage = errorMessage
+		out.ErrorMessagesAsString[i] = errorMessageToString(errorMessage, file)
+
+		if hcljson.IsJSONExpression(validation.Condition) {
+			condition, conditionAsString, d := jsonTemplateToNative(validation.Condition)
+			if d.HasErrors() {
+				return name, model.TranslatedVariable{}, d
+			}
+			if condition != nil {
+				// the validation rules only understand native syntax expressions.
+				out.Variable.Validations[i].Condition = condition
+				out.ConditionsAsString[i] = conditionAsString
+
+				continue
+			}
+		}
 		out.ConditionsAsString[i] = printToString(validation.Condition, file)
 	}
 
 	return name, out, nil
 }
 
-func filterMissingExpression(in hcl.Expression) hcl.Expression {
+// checkTypeAndDefault returns diagnostics if the type constraint of a variable is invalid, or its default can't be
+// evaluated. These are found while the module is read, rather than when the schema is created, so that they are
+// collected along with the other problems in the module when ContinueOnError is set.
+func checkTypeAndDefault(variable model.VariableBlock) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	if variable.Type != nil {
+		_, _, d := typeexpr.TypeConstraintWithDefaults(variable.Type)
+		diags = append(diags, d...)
+	}
+	if variable.Default != nil {
+		_, d := variable.Default.Value(&hcl.EvalContext{})
+		diags = append(diags, d...)
+	}
+
+	return diags
+}
+
+// getSourceLocation returns the file and lines which a block covers, from its header to its closing brace.
+func getSourceLocation(block *hcl.Block) model.SourceLocation {
+	// the JSON syntax doesn't expose the range of the whole body, but its missing item range is the closing brace.
+	end := block.Body.MissingItemRange().End
+	if body, ok := block.Body.(*hclsyntax.Body); ok {
+		end = body.SrcRange.End
+	}
+
+	return model.SourceLocation{
+		Filename:  filepath.Base(block.DefRange.Filename),
+		StartLine: block.DefRange.Start.Line,
+		EndLine:   end.Line,
+	}
+}
+
+func filterMissingExpression(in hcl.Expression, missing hcl.Range) hcl.Expression {
 	// if the start and the end range are the same, this means the field is not
-	// real, so it can be removed.
-	if in.Range().Start.Byte == in.Range().End.Byte {
+	// real, so it can be removed. The JSON syntax doesn't use an empty range for
+	// missing fields, instead it points at the closing brace of the block body.
+	if in.Range().Start.Byte == in.Range().End.Byte || in.Range() == missing {
 		return nil
 	}
 
 	return in
 }
 
+// errorMessageToString returns the value of an error message if it is a constant string, and its source text
+// otherwise, since most error messages which aren't constant refer to the value of the variable.
+func errorMessageToString(in hcl.Expression, f *hcl.File) string {
+	if in == nil {
+		return ""
+	}
+	v, d := in.Value(&hcl.EvalContext{})
+	if !d.HasErrors() && v.IsWhollyKnown() && !v.IsNull() && v.Type() == cty.String {
+		return v.AsString()
+	}
+	if hcljson.IsJSONExpression(in) {
+		return jsonStringValue(in, printToString(in, f))
+	}
+
+	return printToString(in, f)
+}
+
 func printToString(in hcl.Expression, f *hcl.File) string {
 	out := string(in.Range().SliceBytes(f.Bytes))
 
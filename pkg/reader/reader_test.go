@@ -2,9 +2,18 @@
 package reader
 
 import (
+	"bytes"
 	"errors"
+	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
 )
 
 func TestGetVarMap_Required(t *testing.T) {
@@ -16,6 +25,18 @@ func TestGetVarMap_Required(t *testing.T) {
 		"simple-types",
 		"complex-types",
 		"custom-validation",
+		"child-modules",
+		"json-syntax",
+		"override-files",
+		"optional-defaults",
+		"module-manifest",
+		"tofu",
+		"ephemeral",
+		"outputs",
+		"terraform-metadata",
+		"annotations",
+		"unused-variables",
+		"repeated-types",
 	}
 	for i := range testCases {
 		name := testCases[i]
@@ -37,3 +58,387 @@ func TestGetVarMap_Required(t *testing.T) {
 		})
 	}
 }
+
+func TestGetModuleCalls(t *testing.T) {
+	t.Parallel()
+	calls, err := GetModuleCalls("../../test/modules/child-modules")
+	require.NoError(t, err)
+
+	sources := make(map[string]string)
+	for name, call := range calls {
+		sources[name] = call.Source
+	}
+	require.Equal(t, map[string]string{
+		"net":    "./modules/net",
+		"empty":  "./modules/empty",
+		"remote": "hashicorp/consul/aws",
+	}, sources)
+
+	require.True(t, IsLocalSource(calls["net"].Source))
+	require.False(t, IsLocalSource(calls["remote"].Source))
+}
+
+func TestGetModuleManifest(t *testing.T) {
+	t.Parallel()
+	records, err := GetModuleManifest("../../test/modules/module-manifest")
+	require.NoError(t, err)
+
+	// the record for the root module is skipped.
+	require.Len(t, records, 4)
+	require.Equal(t, model.ModuleManifestRecord{
+		Key:     "vpc",
+		Source:  "registry.terraform.io/terraform-aws-modules/vpc/aws",
+		Version: "5.0.0",
+		Dir:     ".terraform/modules/vpc",
+	}, records[2])
+	require.Equal(t, "module.vpc.module.nat", ModuleAddress(records[3].Key))
+
+	_, err = GetModuleManifest("../../test/modules/simple")
+	require.ErrorIs(t, err, ErrManifestNotFound)
+}
+
+func TestGetOutputMap(t *testing.T) {
+	t.Parallel()
+	outputMap, err := GetOutputMap("../../test/modules/outputs")
+	require.NoError(t, err)
+	require.Len(t, outputMap, 9)
+
+	require.Equal(t, "name", outputMap["name"].VariableReference)
+	require.Equal(t, "name", outputMap["json_name"].VariableReference)
+	require.Equal(t, "var.name", outputMap["json_name"].ValueAsString)
+	// a JSON string without an interpolation is a literal string, not a reference.
+	require.Empty(t, outputMap["json_literal"].VariableReference)
+	require.Empty(t, outputMap["greeting"].VariableReference)
+	require.True(t, outputMap["endpoint"].Output.IsSensitive())
+
+	tagCount := outputMap["tag_count"]
+	require.Equal(t, "The number of tags.", *tagCount.Output.Description)
+	require.Equal(t, "length(var.tags)", tagCount.ValueAsString)
+	require.Equal(t, "outputs.tf", tagCount.Location.Filename)
+
+	literalType, ok := GetLiteralValueType(outputMap["ports"].Output.Value)
+	require.True(t, ok)
+	require.Equal(t, []any{"tuple", []any{"number", "number"}}, literalType)
+	_, ok = GetLiteralValueType(outputMap["greeting"].Output.Value)
+	require.False(t, ok)
+}
+
+func TestGetOutputMap_MissingValue(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "outputs.tf"), []byte("output \"a\" {\n  description = \"A\"\n}\n"), 0o600)
+	require.NoError(t, err)
+
+	_, err = GetOutputMap(dir)
+	var diagErr *DiagnosticsError
+	require.ErrorAs(t, err, &diagErr)
+	require.Equal(t, "Missing required argument", diagErr.Diagnostics[0].Summary)
+}
+
+func TestGetTerraformSettings(t *testing.T) {
+	t.Parallel()
+	settings, err := GetTerraformSettings("../../test/modules/terraform-metadata")
+	require.NoError(t, err)
+
+	require.Equal(t, model.TerraformSettings{
+		RequiredVersion: []string{"< 2.0.0", ">= 1.5.0"},
+		RequiredProviders: map[string]model.RequiredProvider{
+			"aws":    {Source: "hashicorp/aws", Version: "~> 5.0"},
+			"null":   {Source: "hashicorp/null"},
+			"random": {Version: "~> 3.0"},
+		},
+		// the backend from the override file replaces the original.
+		Backend: "gcs",
+	}, settings)
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "versions.tf"), []byte("terraform {\n  required_version = 1\n}\n"), 0o600)
+	require.NoError(t, err)
+	_, err = GetTerraformSettings(dir)
+	var diagErr *DiagnosticsError
+	require.ErrorAs(t, err, &diagErr)
+}
+
+func TestGetVarMap_JSONSyntax(t *testing.T) {
+	t.Parallel()
+	varMap, err := GetVarMap("../../test/modules/json-syntax", false)
+	require.NoError(t, err)
+
+	require.Contains(t, varMap, "a_native_variable")
+	require.Equal(t, "list(string)", *varMap["a_native_variable"].TypeAsString)
+
+	age := varMap["age"]
+	require.True(t, age.Required)
+	require.Equal(t, "number", *age.TypeAsString)
+	require.Equal(t, []string{"var.age >= 0 && var.age < 150"}, age.ConditionsAsString)
+	require.Equal(t, []string{"Age must be between 0 and 150."}, age.ErrorMessagesAsString)
+	require.Equal(t, model.SourceLocation{Filename: "variables.tf.json", StartLine: 13, EndLine: 22}, age.Location)
+	require.Equal(t, model.SourceLocation{Filename: "native.tf", StartLine: 3, EndLine: 7},
+		varMap["a_native_variable"].Location)
+
+	require.Equal(t, "object({a = string, b = optional(number)})", *varMap["an_object_with_optional"].TypeAsString)
+	require.Equal(t, []string{`contains(["a", "b"], var.a_string_enum)`}, varMap["a_string_enum"].ConditionsAsString)
+
+	unspecified := varMap["an_unspecified"]
+	require.True(t, unspecified.Required)
+	require.Nil(t, unspecified.TypeAsString)
+	require.Nil(t, unspecified.Variable.Default)
+}
+
+func TestGetVarMap_Overrides(t *testing.T) {
+	t.Parallel()
+	varMap, err := GetVarMap("../../test/modules/override-files", false)
+	require.NoError(t, err)
+
+	name := varMap["name"]
+	require.Equal(t, "Your name.", *name.Variable.Description)
+	require.Equal(t, []string{`can(regex("^[a-z]+$", var.name))`}, name.ConditionsAsString)
+
+	age := varMap["age"]
+	require.Equal(t, "string", *age.TypeAsString)
+	require.Equal(t, "10", *age.DefaultAsString)
+	require.Equal(t, "Your age, as a string.", *age.Variable.Description)
+	require.True(t, *age.Variable.Nullable)
+
+	region := varMap["region"]
+	// the location of a variable is where it's declared, not where it's overridden.
+	require.Equal(t, "variables.tf", region.Location.Filename)
+	require.False(t, region.Required)
+	require.Equal(t, `"eu-west-1"`, *region.DefaultAsString)
+	require.Equal(t, "string", *region.TypeAsString)
+}
+
+func TestGetVarMap_DeclarationOrder(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.tf":        "variable \"zebra\" {}\nvariable \"aardvark\" {}\n",
+		"a.tf":        "variable \"mole\" {}\n",
+		"override.tf": "variable \"aardvark\" {\n  default = 1\n}\n",
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+		require.NoError(t, err)
+	}
+
+	varMap, err := GetVarMap(dir, false)
+	require.NoError(t, err)
+
+	// files are read in lexical order, then blocks in the order they are declared. Overrides keep the original order.
+	require.Equal(t, 0, varMap["mole"].Order)
+	require.Equal(t, 1, varMap["zebra"].Order)
+	require.Equal(t, 2, varMap["aardvark"].Order)
+}
+
+func TestGetVarMapFS(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"modules/vpc/variables.tf":      {Data: []byte("variable \"cidr\" {\n  type = string\n}\n")},
+		"modules/vpc/variables.tf.json": {Data: []byte(`{"variable": {"name": {"default": "vpc"}}}`)},
+		"modules/vpc/broken.tf":         {Data: []byte("variable \"broken\" {\n")},
+		"modules/vpc/nested/other.tf":   {Data: []byte("variable \"other\" {}\n")},
+	}
+
+	varMap, err := GetVarMapFS(fsys, "modules/vpc", GetVarMapOptions{ContinueOnError: true})
+	var diagErr *DiagnosticsError
+	require.ErrorAs(t, err, &diagErr)
+	require.Len(t, varMap, 2)
+	require.Equal(t, "variables.tf", varMap["cidr"].Location.Filename)
+	require.Equal(t, `"vpc"`, *varMap["name"].DefaultAsString)
+
+	// diagnostics refer to files by their path within the file system.
+	require.Equal(t, "modules/vpc/broken.tf", diagErr.Diagnostics[0].Subject.Filename)
+	require.Contains(t, diagErr.Files, "modules/vpc/broken.tf")
+
+	_, err = GetVarMapFS(fsys, "modules/missing", GetVarMapOptions{})
+	require.ErrorIs(t, err, ErrFilesNotFound)
+}
+
+func TestGetVarMap_Dialect(t *testing.T) {
+	t.Parallel()
+	tfPath := "../../test/modules/tofu"
+
+	varMap, err := GetVarMapWithOptions(tfPath, GetVarMapOptions{})
+	require.NoError(t, err)
+	require.Len(t, varMap, 3)
+	require.True(t, varMap["region"].Required)
+	require.True(t, varMap["name"].Required)
+
+	varMap, err = GetVarMapWithOptions(tfPath, GetVarMapOptions{Dialect: DialectOpenTofu})
+	require.NoError(t, err)
+	require.Len(t, varMap, 4)
+	// variables.tofu replaces variables.tf, and override.tofu is applied.
+	require.Equal(t, "variables.tofu", varMap["region"].Location.Filename)
+	require.Equal(t, `"tofu"`, *varMap["name"].DefaultAsString)
+	require.Equal(t, "instances.tf", varMap["instance_count"].Location.Filename)
+	require.Equal(t, "Use name instead.", *varMap["old_name"].Variable.Deprecated)
+}
+
+func TestDialectSelectFiles(t *testing.T) {
+	t.Parallel()
+	names := []string{"a.tf", "a.tofu", "b.tf.json", "b.tofu.json", "c.tf", "c.tofu.json", "d.json", "README.md"}
+
+	require.Equal(t, []string{"a.tf", "b.tf.json", "c.tf"}, DialectTerraform.selectFiles(names))
+	require.Equal(t, []string{"a.tofu", "b.tofu.json", "c.tf", "c.tofu.json"}, DialectOpenTofu.selectFiles(names))
+
+	_, err := ParseDialect("pulumi")
+	require.ErrorIs(t, err, ErrUnknownDialect)
+}
+
+func TestGetVarMap_Comments(t *testing.T) {
+	t.Parallel()
+	varMap, err := GetVarMap("../../test/modules/annotations", false)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"The address which receives alerts.",
+		`@schema format=email title="Admin email" examples=["admin@example.com"]`,
+	}, varMap["admin_email"].Comments)
+	require.Equal(t, []string{"@schema readOnly", "@schema x-ui-widget=password"}, varMap["api_key"].Comments)
+	// comments separated from the block by a blank line, or at the end of a line, are ignored.
+	require.Nil(t, varMap["not_annotated"].Comments)
+	require.Nil(t, varMap["not_annotated"].TypeComments)
+
+	users := varMap["users"].TypeComments
+	require.Equal(t, []string{"@schema format=email"}, users.Child("").Child("email").Comments)
+	require.Equal(t, []string{`@schema title="Display name" minLength=1`}, users.Child("").Child("name").Comments)
+	require.Equal(t, []string{"@schema format=date-time"},
+		users.Child("").Child("roles").Child("").Child("expires").Comments)
+	require.Nil(t, users.Child("").Child("missing"))
+
+	require.Nil(t, varMap["endpoint"].Comments)
+	require.Equal(t, []string{"@schema format=uri"}, varMap["endpoint"].TypeComments.Child("url").Comments)
+}
+
+func TestGetVarMap_CommentDescriptions(t *testing.T) {
+	t.Parallel()
+	tfPath := "../../test/modules/annotations"
+
+	varMap, err := GetVarMap(tfPath, false)
+	require.NoError(t, err)
+	require.Nil(t, varMap["database"].Variable.Description)
+
+	varMap, err = GetVarMapWithOptions(tfPath, GetVarMapOptions{CommentDescriptions: true})
+	require.NoError(t, err)
+	// a description argument is always used instead of the comment.
+	require.Equal(t, "The email address of the administrator.", *varMap["admin_email"].Variable.Description)
+	// comments which only contain annotations don't become descriptions.
+	require.Nil(t, varMap["api_key"].Variable.Description)
+	require.Equal(t, "The settings for the database.\n\nThese are only used when a database is created.",
+		*varMap["database"].Variable.Description)
+
+	database := varMap["database"].TypeComments
+	require.Equal(t, "The size of the disk, in GB.", *database.Child("disk_size").Description)
+	require.Nil(t, database.Child("engine").Description)
+}
+
+func TestGetVariableReferences(t *testing.T) {
+	t.Parallel()
+	references, err := GetVariableReferences("../../test/modules/unused-variables")
+	require.NoError(t, err)
+
+	require.Equal(t, []model.SourceLocation{{Filename: "main.tf", StartLine: 4, EndLine: 4}}, references["region"])
+	// var["tags"] is the same as var.tags.
+	require.Len(t, references["tags"], 1)
+	// references in nested blocks are found.
+	require.Len(t, references["volumes"], 2)
+	// references in JSON templates are found.
+	require.Equal(t, "outputs.tf.json", references["suffix"][0].Filename)
+	// a variable's validation rules don't count as a reference.
+	require.NotContains(t, references, "instance_count")
+}
+
+func TestGetVarMap_OverrideWithoutBase(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "variables.tf"), []byte(`variable "a" {}`), 0o600)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "b_override.tf"), []byte(`variable "b" {}`), 0o600)
+	require.NoError(t, err)
+
+	_, err = GetVarMap(dir, false)
+	var d hcl.Diagnostics
+	require.ErrorAs(t, err, &d)
+	require.Equal(t, "Missing base variable declaration to override", d[0].Summary)
+}
+
+func TestGetVarMap_DuplicateDeclarations(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "a.tf"), []byte("variable \"region\" {}\n"), 0o600)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "b.tf"), []byte("\nvariable \"region\" {\n  default = \"a\"\n}\n"), 0o600)
+	require.NoError(t, err)
+
+	_, err = GetVarMap(dir, false)
+	var diagErr *DiagnosticsError
+	require.ErrorAs(t, err, &diagErr)
+	require.Len(t, diagErr.Diagnostics, 2)
+	require.Contains(t, diagErr.Files, filepath.Join(dir, "a.tf"))
+	require.Contains(t, diagErr.Files, filepath.Join(dir, "b.tf"))
+
+	d := diagErr.Diagnostics[0]
+	require.Equal(t, "Duplicate variable declaration", d.Summary)
+	require.Equal(t, filepath.Join(dir, "b.tf"), d.Subject.Filename)
+	require.Equal(t, 2, d.Subject.Start.Line)
+	require.Contains(t, d.Detail, filepath.Join(dir, "a.tf")+":1,1-18")
+
+	// the first declaration is shown by a second diagnostic.
+	previous := diagErr.Diagnostics[1]
+	require.Equal(t, "Previous variable declaration", previous.Summary)
+	require.Equal(t, filepath.Join(dir, "a.tf"), previous.Subject.Filename)
+	require.Equal(t, 1, previous.Subject.Start.Line)
+
+	// both declarations are rendered with a snippet of the source.
+	var buf bytes.Buffer
+	w := hcl.NewDiagnosticTextWriter(&buf, diagErr.Files, 0, false)
+	err = w.WriteDiagnostics(diagErr.Diagnostics)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "on "+filepath.Join(dir, "a.tf")+" line 1")
+	require.Contains(t, buf.String(), "on "+filepath.Join(dir, "b.tf")+" line 2")
+}
+
+func TestGetVarMapWithOptions_ContinueOnError(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.tf": "variable \"a\" {\n  type = string\n}\n\nvariable \"b\" {\n  nullable = \"x\"\n}\n",
+		"b.tf": "variable \"c\" {\n  type = \n}\n",
+		"c.tf": "variable \"d\" {}\n",
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+		require.NoError(t, err)
+	}
+
+	varMap, err := GetVarMap(dir, false)
+	require.Nil(t, varMap)
+	var diagErr *DiagnosticsError
+	require.ErrorAs(t, err, &diagErr)
+	require.Len(t, diagErr.Diagnostics, 1)
+
+	varMap, err = GetVarMapWithOptions(dir, GetVarMapOptions{ContinueOnError: true})
+	require.ErrorAs(t, err, &diagErr)
+	require.Len(t, diagErr.Diagnostics, 2)
+	require.Equal(t, filepath.Join(dir, "a.tf"), diagErr.Diagnostics[0].Subject.Filename)
+	require.Equal(t, filepath.Join(dir, "b.tf"), diagErr.Diagnostics[1].Subject.Filename)
+
+	require.Len(t, varMap, 2)
+	require.Contains(t, varMap, "a")
+	require.Contains(t, varMap, "d")
+}
+
+func TestGetVarMapWithOptions_ContinueOnErrorCommentDescriptions(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"variables.tf": {Data: []byte("# The CIDR block of the VPC.\nvariable \"cidr\" {\n  type = string\n}\n")},
+		"broken.tf":    {Data: []byte("variable \"broken\" {\n")},
+	}
+
+	varMap, err := GetVarMapFS(fsys, ".", GetVarMapOptions{ContinueOnError: true, CommentDescriptions: true})
+	var diagErr *DiagnosticsError
+	require.ErrorAs(t, err, &diagErr)
+	require.Len(t, varMap, 1)
+	require.NotNil(t, varMap["cidr"].Variable.Description)
+	require.Equal(t, "The CIDR block of the VPC.", *varMap["cidr"].Variable.Description)
+}
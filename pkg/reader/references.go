@@ -0,0 +1,144 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+)
+
+// GetVariableReferences reads all .tf and .tf.json files in a directory and returns the location of each reference
+// to an input variable (var.<name>), keyed by the name of the variable. References inside a variable's own block,
+// such as in its validation rules, are left out, since they don't use the variable anywhere else in the module.
+// In the JSON syntax, references are only found inside string templates, e.g. "${var.name}".
+func GetVariableReferences(path string) (map[string][]model.SourceLocation, error) {
+	return GetVariableReferencesFS(LocalFS{}, filepath.ToSlash(path), DialectTerraform)
+}
+
+// GetVariableReferencesFS is the same as GetVariableReferences, but reads the module in the directory dir of fsys,
+// using the files selected by dialect.
+func GetVariableReferencesFS(fsys fs.FS, dir string, dialect Dialect) (map[string][]model.SourceLocation, error) {
+	files, err := getFiles(fsys, dir, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	references := make(map[string][]model.SourceLocation)
+	for _, fileName := range files {
+		file, d := parseFile(parser, fsys, fileName)
+		if d.HasErrors() {
+			return nil, newDiagnosticsError(d, parser)
+		}
+
+		var traversals []hcl.Traversal
+		if body, ok := file.Body.(*hclsyntax.Body); ok {
+			traversals = nativeBodyTraversals(body, "")
+		} else {
+			traversals, d = jsonBodyTraversals(file.Body)
+			if d.HasErrors() {
+				return nil, newDiagnosticsError(d, parser)
+			}
+		}
+
+		for _, traversal := range traversals {
+			if name, ok := referencedVariable(traversal); ok {
+				references[name] = append(references[name], rangeLocation(traversal.SourceRange()))
+			}
+		}
+	}
+
+	return references, nil
+}
+
+// nativeBodyTraversals returns the traversals in all the expressions of a body and its nested blocks. Traversals of
+// the variable named self are left out.
+func nativeBodyTraversals(body *hclsyntax.Body, self string) []hcl.Traversal {
+	traversals := []hcl.Traversal{}
+	for _, attribute := range body.Attributes {
+		traversals = append(traversals, withoutVariable(attribute.Expr.Variables(), self)...)
+	}
+	for _, block := range body.Blocks {
+		blockSelf := self
+		if block.Type == "variable" && len(block.Labels) == 1 {
+			blockSelf = block.Labels[0]
+		}
+		traversals = append(traversals, nativeBodyTraversals(block.Body, blockSelf)...)
+	}
+
+	return traversals
+}
+
+// jsonBodyTraversals returns the traversals in all the string templates of a JSON file. Variable blocks are decoded
+// with the same schema as GetVarMap, so that both the object and the array form of "variable" are read, and each
+// variable's references to itself can be left out.
+func jsonBodyTraversals(body hcl.Body) ([]hcl.Traversal, hcl.Diagnostics) {
+	content, remain, d := body.PartialContent(fileSchema)
+	if d.HasErrors() {
+		return nil, d
+	}
+
+	traversals := []hcl.Traversal{}
+	for _, block := range content.Blocks {
+		attributes, d := block.Body.JustAttributes()
+		if d.HasErrors() {
+			return nil, d
+		}
+		for _, attribute := range attributes {
+			traversals = append(traversals, withoutVariable(attribute.Expr.Variables(), block.Labels[0])...)
+		}
+	}
+
+	attributes, d := remain.JustAttributes()
+	if d.HasErrors() {
+		return nil, d
+	}
+	for _, attribute := range attributes {
+		traversals = append(traversals, attribute.Expr.Variables()...)
+	}
+
+	return traversals, nil
+}
+
+func withoutVariable(traversals []hcl.Traversal, name string) []hcl.Traversal {
+	out := []hcl.Traversal{}
+	for _, traversal := range traversals {
+		if referenced, ok := referencedVariable(traversal); !ok || referenced != name {
+			out = append(out, traversal)
+		}
+	}
+
+	return out
+}
+
+// referencedVariable returns the name of the variable which a traversal refers to, if it starts with var.<name> or
+// var["<name>"].
+func referencedVariable(traversal hcl.Traversal) (string, bool) {
+	if len(traversal) < 2 || traversal.RootName() != "var" {
+		return "", false
+	}
+	switch step := traversal[1].(type) {
+	case hcl.TraverseAttr:
+		return step.Name, true
+	case hcl.TraverseIndex:
+		if step.Key.Type() == cty.String && step.Key.IsKnown() && !step.Key.IsNull() {
+			return step.Key.AsString(), true
+		}
+	}
+
+	return "", false
+}
+
+func rangeLocation(r hcl.Range) model.SourceLocation {
+	return model.SourceLocation{
+		Filename:  filepath.Base(r.Filename),
+		StartLine: r.Start.Line,
+		EndLine:   r.End.Line,
+	}
+}
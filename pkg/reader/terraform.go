@@ -0,0 +1,177 @@
+// (C) Copyright 2024 Hewlett Packard Enterprise Development LP
+package reader
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/HewlettPackard/terraschema/pkg/model"
+)
+
+var terraformFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type: "terraform",
+		},
+	},
+}
+
+var terraformBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name: "required_version",
+		},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type: "required_providers",
+		},
+		{
+			Type:       "backend",
+			LabelNames: []string{"type"},
+		},
+		{
+			Type: "cloud",
+		},
+	},
+}
+
+// GetTerraformSettings reads the terraform blocks in all .tf and .tf.json files in a directory. Settings from
+// override files replace the settings from other files, in the same way as Terraform.
+func GetTerraformSettings(path string) (model.TerraformSettings, error) {
+	return GetTerraformSettingsFS(LocalFS{}, filepath.ToSlash(path), DialectTerraform)
+}
+
+// GetTerraformSettingsFS is the same as GetTerraformSettings, but reads the module in the directory dir of fsys, using
+// the files selected by dialect.
+func GetTerraformSettingsFS(fsys fs.FS, dir string, dialect Dialect) (model.TerraformSettings, error) {
+	settings := model.TerraformSettings{RequiredProviders: make(map[string]model.RequiredProvider)}
+	files, err := getFiles(fsys, dir, dialect)
+	if err != nil {
+		return settings, err
+	}
+
+	parser := hclparse.NewParser()
+	for _, fileName := range files {
+		file, d := parseFile(parser, fsys, fileName)
+		if d.HasErrors() {
+			return settings, newDiagnosticsError(d, parser)
+		}
+
+		blocks, _, d := file.Body.PartialContent(terraformFileSchema)
+		if d.HasErrors() {
+			return settings, newDiagnosticsError(d, parser)
+		}
+		for _, block := range blocks.Blocks {
+			d := readTerraformBlock(block, isOverrideFile(fileName), &settings)
+			if d.HasErrors() {
+				return settings, newDiagnosticsError(d, parser)
+			}
+		}
+	}
+
+	return settings, nil
+}
+
+// readTerraformBlock adds the settings in a terraform block to settings. A required_version in an override file
+// replaces all the version constraints declared before it, rather than adding to them.
+func readTerraformBlock(block *hcl.Block, override bool, settings *model.TerraformSettings) hcl.Diagnostics {
+	content, _, d := block.Body.PartialContent(terraformBlockSchema)
+	if d.HasErrors() {
+		return d
+	}
+
+	if attr, ok := content.Attributes["required_version"]; ok {
+		version, d := stringValue(attr.Expr)
+		if d.HasErrors() {
+			return d
+		}
+		if override {
+			settings.RequiredVersion = nil
+		}
+		settings.RequiredVersion = append(settings.RequiredVersion, version)
+	}
+
+	for _, inner := range content.Blocks {
+		switch inner.Type {
+		case "required_providers":
+			d := readRequiredProviders(inner, settings.RequiredProviders)
+			if d.HasErrors() {
+				return d
+			}
+		case "backend":
+			settings.Backend = inner.Labels[0]
+		case "cloud":
+			settings.Backend = "cloud"
+		}
+	}
+
+	return nil
+}
+
+// readRequiredProviders adds each provider in a required_providers block to providers. Each provider is either an
+// object with a source and version, or a version constraint on its own, which is the legacy syntax.
+func readRequiredProviders(block *hcl.Block, providers map[string]model.RequiredProvider) hcl.Diagnostics {
+	attrs, d := block.Body.JustAttributes()
+	if d.HasErrors() {
+		return d
+	}
+
+	for name, attr := range attrs {
+		if version, d := stringValue(attr.Expr); !d.HasErrors() {
+			providers[name] = model.RequiredProvider{Version: version}
+
+			continue
+		}
+
+		// the object may contain configuration_aliases, which are references that can't be evaluated, so each of
+		// the items is read separately.
+		items, d := hcl.ExprMap(attr.Expr)
+		if d.HasErrors() {
+			return d
+		}
+		provider := model.RequiredProvider{}
+		for _, item := range items {
+			key, d := stringValue(item.Key)
+			if d.HasErrors() {
+				continue
+			}
+			switch key {
+			case "source":
+				provider.Source, d = stringValue(item.Value)
+			case "version":
+				provider.Version, d = stringValue(item.Value)
+			}
+			if d.HasErrors() {
+				return d
+			}
+		}
+		providers[name] = provider
+	}
+
+	return nil
+}
+
+// stringValue evaluates an expression which must be a constant string, such as a version constraint.
+func stringValue(expr hcl.Expression) (string, hcl.Diagnostics) {
+	v, d := expr.Value(nil)
+	if d.HasErrors() {
+		return "", d
+	}
+	if v.IsNull() || !v.IsKnown() || v.Type() != cty.String {
+		return "", hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value",
+				Detail:   "A string is required.",
+				Subject:  expr.Range().Ptr(),
+			},
+		}
+	}
+
+	return v.AsString(), nil
+}
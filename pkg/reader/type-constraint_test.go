@@ -22,6 +22,18 @@ func TestGetTypeConstraint(t *testing.T) {
 		"simple-types",
 		"complex-types",
 		"custom-validation",
+		"child-modules",
+		"json-syntax",
+		"override-files",
+		"optional-defaults",
+		"module-manifest",
+		"tofu",
+		"ephemeral",
+		"outputs",
+		"terraform-metadata",
+		"annotations",
+		"unused-variables",
+		"repeated-types",
 	}
 	for i := range testCases {
 		name := testCases[i]
@@ -57,3 +69,34 @@ func TestGetTypeConstraint(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTypeConstraintWithDefaults(t *testing.T) {
+	t.Parallel()
+	varMap, err := GetVarMap("../../test/modules/optional-defaults", false)
+	require.NoError(t, err)
+
+	_, defaults, err := GetTypeConstraintWithDefaults(varMap["an_object_with_defaults"].Variable.Type)
+	require.NoError(t, err)
+	require.Equal(t, &TypeDefaults{
+		DefaultValues: map[string]any{
+			"enabled": true,
+			"port":    float64(8080),
+			"tags":    map[string]any{},
+			"nested":  map[string]any{"level": "info", "format": nil},
+		},
+		Children: map[string]*TypeDefaults{
+			"nested": {DefaultValues: map[string]any{"level": "info"}},
+		},
+	}, defaults)
+
+	_, defaults, err = GetTypeConstraintWithDefaults(varMap["a_tuple_with_defaults"].Variable.Type)
+	require.NoError(t, err)
+	v, ok := defaults.Child("1").DefaultValue("retries")
+	require.True(t, ok)
+	require.InDelta(t, 3, v, 0)
+	require.Nil(t, defaults.Child("0"))
+
+	_, defaults, err = GetTypeConstraintWithDefaults(nil)
+	require.NoError(t, err)
+	require.Nil(t, defaults)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // GetTypeConstraint converts the expression into a type constraint and marshals it to JSON.
@@ -14,14 +15,37 @@ import (
 // More info on exactly how this works is here:
 // https://pkg.go.dev/github.com/zclconf/go-cty@v1.14.4/cty#Type.MarshalJSON
 func GetTypeConstraint(in hcl.Expression) (any, error) {
+	tc, _, err := GetTypeConstraintWithDefaults(in)
+
+	return tc, err
+}
+
+// GetTypeConstraintWithDefaults is the same as GetTypeConstraint, but also returns the default values of any
+// optional object attributes declared with optional(<TYPE>, <DEFAULT>). If there are none, the defaults are nil.
+func GetTypeConstraintWithDefaults(in hcl.Expression) (any, *TypeDefaults, error) {
 	if in == nil {
-		return "any", nil
+		return "any", nil, nil
 	}
 
-	t, _, d := typeexpr.TypeConstraintWithDefaults(in) // do something with default value?
+	t, defaults, d := typeexpr.TypeConstraintWithDefaults(in)
 	if d.HasErrors() {
-		return nil, fmt.Errorf("could not parse type constraint from expression: %w", d)
+		return nil, nil, fmt.Errorf("could not parse type constraint from expression: %w", d)
+	}
+
+	typeInterface, err := typeToJSONObject(t)
+	if err != nil {
+		return nil, nil, err
 	}
+
+	translatedDefaults, err := translateDefaults(defaults)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not convert optional attribute defaults to JSON: %w", err)
+	}
+
+	return typeInterface, translatedDefaults, nil
+}
+
+func typeToJSONObject(t cty.Type) (any, error) {
 	typeJSON, err := t.MarshalJSON()
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal constraint to JSON: %w", err)
@@ -35,3 +59,72 @@ func GetTypeConstraint(in hcl.Expression) (any, error) {
 
 	return typeInterface, nil
 }
+
+// TypeDefaults contains the default values of optional object attributes in a type constraint, at every level of
+// nesting. It has the same structure as typeexpr.Defaults, but the values are converted so that they can be
+// marshaled to JSON.
+type TypeDefaults struct {
+	// DefaultValues contains the default value for each optional object attribute, indexed by attribute name.
+	DefaultValues map[string]any `json:"default_values,omitempty"`
+	// Children contains the defaults for types nested in this one. Object attributes are indexed by name, tuple
+	// elements by their position, and the single element type of a list, set or map by "".
+	Children map[string]*TypeDefaults `json:"children,omitempty"`
+}
+
+// Child returns the defaults for a nested type, or nil if there are none. It is safe to call on a nil value.
+func (d *TypeDefaults) Child(key string) *TypeDefaults {
+	if d == nil {
+		return nil
+	}
+
+	return d.Children[key]
+}
+
+// DefaultValue returns the default value of an optional object attribute, and whether it has one. It is safe to
+// call on a nil value.
+func (d *TypeDefaults) DefaultValue(attribute string) (any, bool) {
+	if d == nil {
+		return nil, false
+	}
+	v, ok := d.DefaultValues[attribute]
+
+	return v, ok
+}
+
+func translateDefaults(in *typeexpr.Defaults) (*TypeDefaults, error) {
+	if in == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	out := &TypeDefaults{}
+	for attribute, value := range in.DefaultValues {
+		// a default value for an object can itself be missing optional attributes, in which case Terraform
+		// fills them in with their own defaults.
+		if child, ok := in.Children[attribute]; ok {
+			value = child.Apply(value)
+		}
+		v, err := ValueToJSONObject(value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", attribute, err)
+		}
+		if out.DefaultValues == nil {
+			out.DefaultValues = make(map[string]any)
+		}
+		out.DefaultValues[attribute] = v
+	}
+	for key, child := range in.Children {
+		translated, err := translateDefaults(child)
+		if err != nil {
+			return nil, err
+		}
+		if translated == nil {
+			continue
+		}
+		if out.Children == nil {
+			out.Children = make(map[string]*TypeDefaults)
+		}
+		out.Children[key] = translated
+	}
+
+	return out, nil
+}
@@ -22,6 +22,18 @@ func TestExpressionToJSONObject_Default(t *testing.T) {
 		"simple-types",
 		"complex-types",
 		"custom-validation",
+		"child-modules",
+		"json-syntax",
+		"override-files",
+		"optional-defaults",
+		"module-manifest",
+		"tofu",
+		"ephemeral",
+		"outputs",
+		"terraform-metadata",
+		"annotations",
+		"unused-variables",
+		"repeated-types",
 	}
 	for i := range testCases {
 		name := testCases[i]
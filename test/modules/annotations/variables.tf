@@ -0,0 +1,68 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+# The address which receives alerts.
+# @schema format=email title="Admin email" examples=["admin@example.com"]
+variable "admin_email" {
+    type        = string
+    description = "The email address of the administrator."
+}
+
+// @schema readOnly
+// @schema x-ui-widget=password
+variable "api_key" {
+    type      = string
+    sensitive = true
+}
+
+variable "users" {
+    type = list(object({
+        # @schema format=email
+        email = string
+        // @schema title="Display name" minLength=1
+        name = optional(string)
+        roles = optional(map(object({
+            # @schema format=date-time
+            expires = string
+        })))
+    }))
+    default = []
+}
+
+variable "endpoint" {
+    # this comment isn't directly above the block, so it isn't used.
+    type = object({
+        # @schema format=uri
+        url = string
+    })
+    default = null
+}
+
+# @schema deprecated=true
+
+variable "not_annotated" {
+    type    = number # @schema minimum=1
+    default = 1
+}
+
+// The settings for the database.
+//
+// These are only used when a database is created.
+variable "database" {
+    type = object({
+        # The size of the disk, in GB.
+        # @schema minimum=20
+        disk_size = number
+        // @schema title=Engine
+        engine = optional(string, "postgres")
+        # The name of the database.
+        name = string
+    })
+    default = null
+}
+
+# @schema format=hostname minLength=3 title=Hostname
+variable "hostname" {
+    type     = string
+    nullable = true
+    default  = null
+}
@@ -0,0 +1,15 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+module "net" {
+    source = "./modules/net"
+    cidr   = var.cidr
+}
+
+module "empty" {
+    source = "./modules/empty"
+}
+
+module "remote" {
+    source  = "hashicorp/consul/aws"
+    version = "0.1.0"
+}
@@ -0,0 +1,24 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "cidr" {
+    type = string
+}
+
+variable "subnet_count" {
+    type    = number
+    default = 2
+    validation {
+        condition     = var.subnet_count > 0
+        error_message = "There must be at least one subnet."
+    }
+}
+
+module "subnet" {
+    source = "./modules/subnet"
+    count  = var.subnet_count
+    name   = "subnet-${count.index}"
+}
+
+module "loop" {
+    source = "../net"
+}
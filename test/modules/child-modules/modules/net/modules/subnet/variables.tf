@@ -0,0 +1,6 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "name" {
+    type        = string
+    description = "The name of the subnet."
+}
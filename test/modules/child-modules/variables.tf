@@ -0,0 +1,6 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "cidr" {
+    type        = string
+    description = "The CIDR block of the network."
+}
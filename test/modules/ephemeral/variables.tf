@@ -0,0 +1,19 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "session_token" {
+    type        = string
+    description = "A short-lived token used while applying the configuration."
+    ephemeral   = true
+    sensitive   = true
+}
+
+variable "debug" {
+    type      = bool
+    default   = false
+    ephemeral = true
+}
+
+variable "name" {
+    type      = string
+    ephemeral = false
+}
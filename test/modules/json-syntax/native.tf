@@ -0,0 +1,7 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "a_native_variable" {
+    type        = list(string)
+    description = "This variable is declared in native syntax."
+    default     = ["a"]
+}
@@ -0,0 +1,6 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "replicas" {
+    type        = number
+    description = "The number of replicas."
+}
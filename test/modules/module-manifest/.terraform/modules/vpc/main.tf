@@ -0,0 +1,15 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "cidr" {
+    type    = string
+    default = "10.0.0.0/16"
+}
+
+variable "azs" {
+    type    = list(string)
+    default = []
+}
+
+module "nat" {
+    source = "./modules/nat"
+}
@@ -0,0 +1,6 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "single_nat_gateway" {
+    type    = bool
+    default = false
+}
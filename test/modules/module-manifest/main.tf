@@ -0,0 +1,19 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "environment" {
+    type        = string
+    description = "The environment to deploy to."
+}
+
+module "vpc" {
+    source  = "terraform-aws-modules/vpc/aws"
+    version = "5.0.0"
+}
+
+module "app" {
+    source = "git::https://example.com/app.git?ref=v1.2.0"
+}
+
+module "local" {
+    source = "./modules/local"
+}
@@ -0,0 +1,5 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "name" {
+    type = string
+}
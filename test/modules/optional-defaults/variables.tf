@@ -0,0 +1,47 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "an_object_with_defaults" {
+    type = object({
+        name    = string
+        enabled = optional(bool, true)
+        port    = optional(number, 8080)
+        tags    = optional(map(string), {})
+        nested = optional(object({
+            level  = optional(string, "info")
+            format = optional(string)
+        }), {})
+    })
+    description = "An object with optional attributes which have default values"
+}
+
+variable "a_list_of_objects_with_defaults" {
+    type = list(object({
+        name = string
+        size = optional(number, 1)
+    }))
+    default = []
+}
+
+variable "a_map_of_objects_with_defaults" {
+    type = map(object({
+        cidr   = string
+        public = optional(bool, false)
+    }))
+    default = {}
+}
+
+variable "a_tuple_with_defaults" {
+    type = tuple([
+        string,
+        object({
+            retries = optional(number, 3)
+        }),
+    ])
+}
+
+variable "a_set_of_objects_with_defaults" {
+    type = set(object({
+        protocol = optional(string, "tcp")
+    }))
+    default = []
+}
@@ -0,0 +1,34 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+output "name" {
+    value       = var.name
+    description = "The name which was given."
+}
+
+output "tags" {
+    value = var.tags
+}
+
+output "settings" {
+    value = var.settings
+}
+
+output "greeting" {
+    value = "Hello, ${var.name}!"
+}
+
+output "ports" {
+    value = [80, 443]
+}
+
+output "endpoint" {
+    value = {
+        host = "example.com"
+        port = 443
+    }
+    sensitive = true
+}
+
+output "tag_count" {
+    value = length(var.tags)
+}
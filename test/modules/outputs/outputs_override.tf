@@ -0,0 +1,5 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+output "tag_count" {
+    description = "The number of tags."
+}
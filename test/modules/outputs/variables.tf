@@ -0,0 +1,20 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "name" {
+    type        = string
+    description = "Your name."
+}
+
+variable "tags" {
+    type    = map(string)
+    default = {}
+}
+
+variable "settings" {
+    type = object({
+        replicas = optional(number, 1)
+        labels   = list(string)
+    })
+    nullable = true
+    default  = null
+}
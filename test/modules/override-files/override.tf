@@ -0,0 +1,8 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "name" {
+    validation {
+        condition     = can(regex("^[a-z]+$", var.name))
+        error_message = "Name must only contain lowercase letters."
+    }
+}
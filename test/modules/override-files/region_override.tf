@@ -0,0 +1,10 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "region" {
+    default = "eu-west-1"
+}
+
+variable "age" {
+    type     = string
+    nullable = true
+}
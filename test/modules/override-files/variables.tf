@@ -0,0 +1,25 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "name" {
+    type        = string
+    description = "Your name."
+    validation {
+        condition     = length(var.name) > 0
+        error_message = "Name must not be empty."
+    }
+    validation {
+        condition     = length(var.name) < 100
+        error_message = "Name must be less than 100 characters."
+    }
+}
+
+variable "age" {
+    type        = number
+    description = "Your age."
+    default     = 10
+}
+
+variable "region" {
+    type        = string
+    description = "The region to deploy to."
+}
@@ -0,0 +1,63 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "primary_database" {
+    type = object({
+        engine  = string
+        version = string
+        backup = object({
+            enabled        = bool
+            retention_days = optional(number, 7)
+        })
+    })
+    description = "The primary database."
+}
+
+variable "replica_database" {
+    type = object({
+        engine  = string
+        version = string
+        backup = object({
+            enabled        = bool
+            retention_days = optional(number, 7)
+        })
+    })
+    description = "The read replica."
+    validation {
+        condition     = length(var.replica_database) == 3
+        error_message = "All the settings of the replica must be set."
+    }
+}
+
+variable "logs_backup" {
+    type = object({
+        enabled        = bool
+        retention_days = optional(number, 7)
+    })
+    default = {
+        enabled = false
+    }
+}
+
+variable "archive_backup" {
+    type = object({
+        enabled        = bool
+        retention_days = optional(number, 30)
+    })
+}
+
+variable "users" {
+    type = list(object({
+        name  = string
+        email = string
+    }))
+    default = []
+}
+
+variable "admins" {
+    type = map(object({
+        name  = string
+        email = string
+    }))
+    default  = {}
+    nullable = true
+}
@@ -0,0 +1,45 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "password" {
+    type        = string
+    description = "The admin password."
+    sensitive   = true
+    default     = "hunter2"
+    validation {
+        condition     = length(var.password) >= 8
+        error_message = "The password must be at least 8 characters long."
+    }
+}
+
+variable "api_token" {
+    type      = string
+    sensitive = true
+    nullable  = true
+}
+
+variable "credentials" {
+    type = object({
+        username = string
+        password = string
+    })
+    sensitive = true
+    default = {
+        username = "admin"
+        password = "hunter2"
+    }
+}
+
+variable "not_sensitive" {
+    type      = string
+    sensitive = false
+    default   = "visible"
+}
+
+
+variable "database" {
+    type = object({
+        host     = string
+        password = optional(string, "hunter2")
+    })
+    sensitive = true
+}
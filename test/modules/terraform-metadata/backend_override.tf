@@ -0,0 +1,7 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+terraform {
+    backend "gcs" {
+        bucket = "example"
+    }
+}
@@ -0,0 +1,19 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+terraform {
+    required_version = ">= 1.5.0"
+
+    required_providers {
+        aws = {
+            source                = "hashicorp/aws"
+            version               = "~> 5.0"
+            configuration_aliases = [aws.east]
+        }
+        random = "~> 3.0"
+    }
+
+    backend "s3" {
+        bucket = "example"
+        key    = "terraform.tfstate"
+    }
+}
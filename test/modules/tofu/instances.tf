@@ -0,0 +1,6 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "instance_count" {
+    type    = number
+    default = 1
+}
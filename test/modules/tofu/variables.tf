@@ -0,0 +1,10 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "region" {
+    type        = string
+    description = "The region to deploy to."
+}
+
+variable "name" {
+    type = string
+}
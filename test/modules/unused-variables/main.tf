@@ -0,0 +1,18 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+provider "aws" {
+    region = var.region
+}
+
+resource "aws_instance" "example" {
+    ami  = var.ami_id
+    tags = merge(var["tags"], { Name = "example" })
+
+    dynamic "ebs_block_device" {
+        for_each = var.volumes
+        content {
+            device_name = ebs_block_device.value.name
+            volume_size = var.volumes[ebs_block_device.key].size
+        }
+    }
+}
@@ -0,0 +1,29 @@
+# Copyright 2024 Hewlett Packard Enterprise Development LP
+
+variable "region" {
+    type = string
+}
+
+variable "tags" {
+    type    = map(string)
+    default = {}
+}
+
+variable "instance_count" {
+    type    = number
+    default = 1
+    validation {
+        condition     = var.instance_count > 0
+        error_message = "At least one instance is required."
+    }
+}
+
+variable "name" {
+    type    = string
+    default = "example"
+}
+
+variable "legacy" {
+    type    = string
+    default = ""
+}